@@ -0,0 +1,8 @@
+// Package api holds the OpenAPI specification of the REST API. It is embedded
+// into the binary so that requests can be validated against it at runtime.
+package api
+
+import _ "embed"
+
+//go:embed openapi.yaml
+var Spec []byte
@@ -1,21 +1,30 @@
 package main
 
 import (
+	"ReilBleem13/pull_requests_service/internal/auth"
 	"ReilBleem13/pull_requests_service/internal/config"
+	grpctransport "ReilBleem13/pull_requests_service/internal/grpc"
 	"ReilBleem13/pull_requests_service/internal/handler"
+	"ReilBleem13/pull_requests_service/internal/metrics"
 	"ReilBleem13/pull_requests_service/internal/repository"
 	"ReilBleem13/pull_requests_service/internal/repository/database"
 	"ReilBleem13/pull_requests_service/internal/service"
+	"ReilBleem13/pull_requests_service/internal/webhook"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/theartofdevel/logging"
+	grpclib "google.golang.org/grpc"
 )
 
 func main() {
@@ -37,6 +46,11 @@ func main() {
 
 	ctx = logging.ContextWithLogger(ctx, logger)
 
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		log.Fatalf("unable to configure authentication: %v", err)
+	}
+
 	db, err := database.NewPostgresDB(ctx, cfg.Database.DSN())
 	if err != nil {
 		log.Fatal("unable to create database connection")
@@ -46,10 +60,42 @@ func main() {
 	userRepo := repository.NewUserRepository(db.Client())
 	teamRepo := repository.NewTeamRepository(db.Client())
 	prRepo := repository.NewPullRequestRepository(db.Client())
+	webhookRepo := repository.NewWebhookRepository(db.Client())
+
+	appMetrics := metrics.New(db.Client().DB)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, webhookRepo, logger,
+		service.WithMetrics(appMetrics),
+		service.WithIdempotencyKeyTTL(cfg.App.IdempotencyKeyTTL),
+	)
+
+	dispatcher := webhook.NewDispatcher(webhookRepo, logger, webhook.Config{
+		PollInterval: cfg.Webhook.PollInterval,
+		BatchSize:    cfg.Webhook.BatchSize,
+		MaxAttempts:  cfg.Webhook.MaxAttempts,
+		BaseBackoff:  cfg.Webhook.BaseBackoff,
+		MaxBackoff:   cfg.Webhook.MaxBackoff,
+		Timeout:      cfg.Webhook.Timeout,
+	})
+
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(ctx)
+	}()
+
+	cleanupDone := make(chan struct{})
+	go func() {
+		defer close(cleanupDone)
+		svc.RunIdempotencyKeyCleanup(ctx, cfg.App.IdempotencyCleanupInterval)
+	}()
 
-	svc := service.NewService(userRepo, teamRepo, prRepo, logger)
+	readiness := handler.NewReadiness(db, cfg.App.ReadyTimeout)
 
-	httpMux := handler.NewRouter(svc, logger)
+	httpMux, err := handler.NewRouter(svc, logger, appMetrics, readiness, authenticator)
+	if err != nil {
+		log.Fatalf("unable to create http router: %v", err)
+	}
 	httpAddr := ":" + cfg.App.Port
 	httpServer := handler.NewServer(httpAddr, httpMux)
 
@@ -62,8 +108,27 @@ func main() {
 		}
 	}()
 
+	grpcServer := grpctransport.NewServer(svc, logger, authenticator)
+	grpcAddr := ":" + cfg.App.GRPCPort
+
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		logging.L(ctx).Error("failed to listen for grpc", logging.ErrAttr(err))
+		return
+	}
+
+	grpcErrCh := make(chan error)
+
+	go func() {
+		logging.L(ctx).Info("starting grpc server", logging.StringAttr("addr", grpcAddr))
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			grpcErrCh <- err
+		}
+	}()
+
 	logging.WithAttrs(ctx,
 		logging.StringAttr("Port", cfg.App.Port),
+		logging.StringAttr("GRPC_Port", cfg.App.GRPCPort),
 		logging.StringAttr("Mode", cfg.App.Mode),
 		logging.StringAttr("DB_Host", cfg.Database.Host),
 		logging.StringAttr("DB_Port", cfg.Database.Port),
@@ -79,23 +144,85 @@ func main() {
 	case err := <-httpErrCh:
 		logging.L(ctx).Error("http server failed", logging.ErrAttr(err))
 		return
+	case err := <-grpcErrCh:
+		logging.L(ctx).Error("grpc server failed", logging.ErrAttr(err))
+		return
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	readiness.StartDraining()
+	logging.L(ctx).Info("reporting not ready, waiting for load balancers to drain",
+		logging.StringAttr("drain", cfg.App.ShutdownDrain.String()),
+	)
+	time.Sleep(cfg.App.ShutdownDrain)
+
+	// ctx is already cancelled here, the servers get a fresh deadline to
+	// finish the requests in flight
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		logging.L(ctx).Error("http server forced shutdown", logging.ErrAttr(err))
-	}
+	var serversDone sync.WaitGroup
+	serversDone.Add(2)
+	go func() {
+		defer serversDone.Done()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logging.L(ctx).Error("http server forced shutdown", logging.ErrAttr(err))
+		}
+	}()
+	go func() {
+		defer serversDone.Done()
+		if err := stopGRPC(shutdownCtx, grpcServer); err != nil {
+			logging.L(ctx).Error("grpc server forced shutdown", logging.ErrAttr(err))
+		}
+	}()
+	serversDone.Wait()
+
+	<-dispatcherDone
+	<-cleanupDone
 
 	if err := db.Close(); err != nil {
 		logging.L(ctx).Error("failed to close database connection", logging.ErrAttr(err))
 	}
 
-	<-shutdownCtx.Done()
 	if shutdownCtx.Err() == context.DeadlineExceeded {
 		logging.L(ctx).Warn("graceful shutdown timed out")
 	} else {
 		logging.L(ctx).Info("graceful shutdown completed...")
 	}
 }
+
+// stopGRPC lets running calls finish and closes the remaining connections
+// once ctx is done.
+func stopGRPC(ctx context.Context, server *grpclib.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
+
+func newAuthenticator(cfg config.Auth) (auth.Authenticator, error) {
+	var chain auth.Chain
+	if cfg.JWTSecret != "" {
+		chain = append(chain, auth.NewJWTAuthenticator(cfg.JWTSecret))
+	}
+	if cfg.TokensFile != "" {
+		tokens, err := auth.LoadStaticTokens(cfg.TokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("load static tokens: %w", err)
+		}
+		chain = append(chain, tokens)
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("set AUTH_JWT_SECRET or AUTH_TOKENS_FILE")
+	}
+	return chain, nil
+}
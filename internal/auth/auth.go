@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleUser  Role = "user"
+)
+
+func (r Role) IsValid() bool {
+	return r == RoleAdmin || r == RoleUser
+}
+
+// Allows reports whether a caller with role r may use an endpoint that
+// requires role required. Admins may use every endpoint.
+func (r Role) Allows(required Role) bool {
+	return r == RoleAdmin || r == required
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Authenticator resolves a bearer token to the caller it was issued for.
+type Authenticator interface {
+	Authenticate(token string) (*Principal, error)
+}
+
+// Chain tries the authenticators in order and accepts the first match.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(token string) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(token)
+		if err == nil {
+			return principal, nil
+		}
+		if !errors.Is(err, ErrInvalidToken) {
+			return nil, err
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+type claims struct {
+	Role Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// JWTAuthenticator accepts HS256 tokens signed with secret that carry the
+// caller in "sub" and its role in "role". exp and nbf are enforced when set.
+type JWTAuthenticator struct {
+	secret []byte
+	parser *jwt.Parser
+}
+
+func NewJWTAuthenticator(secret string) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		secret: []byte(secret),
+		parser: jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})),
+	}
+}
+
+func (a *JWTAuthenticator) Authenticate(token string) (*Principal, error) {
+	var c claims
+	_, err := a.parser.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if c.Subject == "" || !c.Role.IsValid() {
+		return nil, fmt.Errorf("%w: sub and a valid role are required", ErrInvalidToken)
+	}
+	return &Principal{Subject: c.Subject, Role: c.Role}, nil
+}
+
+// StaticTokens maps long-lived tokens to their callers. Tokens are kept as
+// SHA-256 digests, so that lookups don't leak how much of a guess matched.
+type StaticTokens map[[sha256.Size]byte]Principal
+
+// LoadStaticTokens reads a token file with one "<token> <role> <subject>"
+// entry per line. Empty lines and lines starting with # are skipped.
+func LoadStaticTokens(path string) (StaticTokens, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := make(StaticTokens)
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected <token> <role> <subject>", path, line)
+		}
+
+		role := Role(fields[1])
+		if !role.IsValid() {
+			return nil, fmt.Errorf("%s:%d: unknown role %q", path, line, fields[1])
+		}
+		digest := sha256.Sum256([]byte(fields[0]))
+		if _, ok := tokens[digest]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate token", path, line)
+		}
+		tokens[digest] = Principal{Subject: fields[2], Role: role}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (t StaticTokens) Authenticate(token string) (*Principal, error) {
+	principal, ok := t[sha256.Sum256([]byte(token))]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return &principal, nil
+}
@@ -0,0 +1,140 @@
+package auth_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "test-secret"
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	authenticator := auth.NewJWTAuthenticator(secret)
+
+	t.Run("valid token", func(t *testing.T) {
+		token := signToken(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{
+			"sub":  "u1",
+			"role": "admin",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		})
+
+		principal, err := authenticator.Authenticate(token)
+		require.NoError(t, err)
+		assert.Equal(t, auth.Principal{Subject: "u1", Role: auth.RoleAdmin}, *principal)
+	})
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		key    any
+		claims jwt.MapClaims
+	}{
+		{
+			name:   "wrong secret",
+			method: jwt.SigningMethodHS256,
+			key:    []byte("other"),
+			claims: jwt.MapClaims{"sub": "u1", "role": "user"},
+		},
+		{
+			name:   "expired",
+			method: jwt.SigningMethodHS256,
+			key:    []byte(secret),
+			claims: jwt.MapClaims{"sub": "u1", "role": "user", "exp": time.Now().Add(-time.Minute).Unix()},
+		},
+		{
+			name:   "unexpected algorithm",
+			method: jwt.SigningMethodHS512,
+			key:    []byte(secret),
+			claims: jwt.MapClaims{"sub": "u1", "role": "user"},
+		},
+		{
+			name:   "unknown role",
+			method: jwt.SigningMethodHS256,
+			key:    []byte(secret),
+			claims: jwt.MapClaims{"sub": "u1", "role": "root"},
+		},
+		{
+			name:   "no subject",
+			method: jwt.SigningMethodHS256,
+			key:    []byte(secret),
+			claims: jwt.MapClaims{"role": "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(signToken(t, tt.method, tt.key, tt.claims))
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+func TestStaticTokens(t *testing.T) {
+	writeFile := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "tokens")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("load and authenticate", func(t *testing.T) {
+		tokens, err := auth.LoadStaticTokens(writeFile(t, `
+# deploy bot
+tok-admin admin ci-bot
+
+tok-user user u1
+`))
+		require.NoError(t, err)
+
+		principal, err := tokens.Authenticate("tok-user")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Principal{Subject: "u1", Role: auth.RoleUser}, *principal)
+
+		_, err = tokens.Authenticate("tok-unknown")
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("reject malformed files", func(t *testing.T) {
+		_, err := auth.LoadStaticTokens(writeFile(t, "tok admin"))
+		assert.ErrorContains(t, err, ":1: expected")
+
+		_, err = auth.LoadStaticTokens(writeFile(t, "tok root u1"))
+		assert.ErrorContains(t, err, "unknown role")
+
+		_, err = auth.LoadStaticTokens(writeFile(t, "tok admin a\ntok user b"))
+		assert.ErrorContains(t, err, ":2: duplicate token")
+	})
+}
+
+func TestChain(t *testing.T) {
+	tokens := auth.StaticTokens{}
+	chain := auth.Chain{auth.NewJWTAuthenticator(secret), tokens}
+
+	token := signToken(t, jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": "u2", "role": "user"})
+	principal, err := chain.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "u2", principal.Subject)
+
+	_, err = chain.Authenticate("garbage")
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, auth.RoleAdmin.Allows(auth.RoleUser))
+	assert.True(t, auth.RoleAdmin.Allows(auth.RoleAdmin))
+	assert.True(t, auth.RoleUser.Allows(auth.RoleUser))
+	assert.False(t, auth.RoleUser.Allows(auth.RoleAdmin))
+}
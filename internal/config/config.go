@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -11,11 +12,26 @@ import (
 type Config struct {
 	App      App
 	Database Database
+	Webhook  Webhook
+	Auth     Auth
 }
 
 type App struct {
 	Mode string `env:"MODE" env-required:"debug"` // debug, release
 	Port string `env:"PORT" env-required:"8080"`
+	// GRPCPort serves the gRPC API next to the REST one.
+	GRPCPort string `env:"GRPC_PORT" env-default:"9090"`
+
+	// ReadyTimeout bounds the database checks of the readiness probe.
+	ReadyTimeout time.Duration `env:"READY_TIMEOUT" env-default:"2s"`
+	// ShutdownDrain is how long the instance reports not ready before the
+	// server stops accepting requests, so that load balancers can drain it.
+	ShutdownDrain time.Duration `env:"SHUTDOWN_DRAIN" env-default:"5s"`
+
+	// IdempotencyKeyTTL is how long Idempotency-Key values of PR creation
+	// are remembered; older keys are purged every IdempotencyCleanupInterval.
+	IdempotencyKeyTTL          time.Duration `env:"IDEMPOTENCY_KEY_TTL" env-default:"24h"`
+	IdempotencyCleanupInterval time.Duration `env:"IDEMPOTENCY_CLEANUP_INTERVAL" env-default:"1h"`
 }
 
 type Database struct {
@@ -27,6 +43,22 @@ type Database struct {
 	SSLMode  string `env:"POSTGRES_SSLMODE" env-required:"true"`
 }
 
+type Webhook struct {
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `env:"WEBHOOK_BATCH_SIZE" env-default:"50"`
+	MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	BaseBackoff  time.Duration `env:"WEBHOOK_BASE_BACKOFF" env-default:"5s"`
+	MaxBackoff   time.Duration `env:"WEBHOOK_MAX_BACKOFF" env-default:"1h"`
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+}
+
+// Auth configures how bearer tokens are validated. At least one of the
+// sources must be set; with both, static tokens are tried after JWTs.
+type Auth struct {
+	JWTSecret  string `env:"AUTH_JWT_SECRET"`
+	TokensFile string `env:"AUTH_TOKENS_FILE"`
+}
+
 func (d Database) DSN() string {
 	return fmt.Sprintf(
 		`host=%s port=%s user=%s password=%s dbname=%s sslmode=%s`,
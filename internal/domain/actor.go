@@ -0,0 +1,32 @@
+package domain
+
+import "context"
+
+type (
+	actorKey struct{}
+	adminKey struct{}
+)
+
+// ContextWithActor stores the id of whoever performs the request, so that
+// recorded history can tell who made a change.
+func ContextWithActor(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actorID)
+}
+
+// ActorFromContext returns the actor id or an empty string if it is unknown.
+func ActorFromContext(ctx context.Context) string {
+	actorID, _ := ctx.Value(actorKey{}).(string)
+	return actorID
+}
+
+// ContextWithAdmin marks the actor as an administrator, who may act on
+// behalf of other users.
+func ContextWithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// ActorIsAdmin reports whether the actor is an administrator.
+func ActorIsAdmin(ctx context.Context) bool {
+	isAdmin, _ := ctx.Value(adminKey{}).(bool)
+	return isAdmin
+}
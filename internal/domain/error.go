@@ -1,5 +1,7 @@
 package domain
 
+import "errors"
+
 type ErrorCode string
 
 const (
@@ -8,16 +10,27 @@ const (
 	CodePRMerged    ErrorCode = "PR_MERGED"
 	CodeNotAssigned ErrorCode = "NOT_ASSIGNED"
 	CodeNoCandidate ErrorCode = "NO_CANDIDATE"
-	CodeNotFound    ErrorCode = "NOT_FOUND"
+	CodeNotApproved ErrorCode = "NOT_APPROVED"
+
+	CodeInvalidTransition ErrorCode = "INVALID_TRANSITION"
+	CodeNotFound          ErrorCode = "NOT_FOUND"
+
+	CodeWebhookExists ErrorCode = "WEBHOOK_EXISTS"
+
+	CodeIdempotencyKeyReused ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+
+	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	CodeForbidden    ErrorCode = "FORBIDDEN"
 
 	CodeInvalidRequest ErrorCode = "INVALID_REQUEST"
 	CodeInternalError  ErrorCode = "INTERNAL_SERVER_ERROR"
 )
 
 type AppError struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
-	Cause   error     `json:"-"`
+	Code    ErrorCode    `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	Cause   error        `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -29,6 +42,12 @@ func (e *AppError) Error() string {
 
 func (e *AppError) Unwrap() error { return e.Cause }
 
+// HasCode reports whether err wraps an AppError with the given code.
+func HasCode(err error, code ErrorCode) bool {
+	var appErr *AppError
+	return errors.As(err, &appErr) && appErr.Code == code
+}
+
 func ErrTeamExists() error {
 	return &AppError{Code: CodeTeamExists, Message: "team_name already exists"}
 }
@@ -41,6 +60,10 @@ func ErrPRMerged() error {
 	return &AppError{Code: CodePRMerged, Message: "cannot reassign on merged PR"}
 }
 
+func ErrReassignOnClosedPR(status PRStatus) error {
+	return &AppError{Code: CodeInvalidTransition, Message: "cannot reassign reviewers of " + string(status) + " PR"}
+}
+
 func ErrNotAssigned() error {
 	return &AppError{Code: CodeNotAssigned, Message: "reviewer is not assigned to this PR"}
 }
@@ -49,10 +72,42 @@ func ErrNoCandidate() error {
 	return &AppError{Code: CodeNoCandidate, Message: "no active replacement candidate in team"}
 }
 
+func ErrReviewOnMergedPR() error {
+	return &AppError{Code: CodePRMerged, Message: "cannot review merged PR"}
+}
+
+func ErrNotApproved(msg string) error {
+	return &AppError{Code: CodeNotApproved, Message: msg}
+}
+
+func ErrInvalidTransition(from, to PRStatus) error {
+	return &AppError{Code: CodeInvalidTransition, Message: "cannot move PR from " + string(from) + " to " + string(to)}
+}
+
 func ErrNotFound() error {
 	return &AppError{Code: CodeNotFound, Message: "resource not found"}
 }
 
+func ErrWebhookExists() error {
+	return &AppError{Code: CodeWebhookExists, Message: "webhook with this url already exists for the team"}
+}
+
+func ErrIdempotencyKeyReused() error {
+	return &AppError{Code: CodeIdempotencyKeyReused, Message: "idempotency key was already used with a different request"}
+}
+
+func ErrUnauthorized() error {
+	return &AppError{Code: CodeUnauthorized, Message: "missing or invalid bearer token"}
+}
+
+func ErrForbidden() error {
+	return &AppError{Code: CodeForbidden, Message: "the token's role is not allowed to use this endpoint"}
+}
+
+func ErrReviewAsOtherUser() error {
+	return &AppError{Code: CodeForbidden, Message: "only the reviewer or an admin can submit the review"}
+}
+
 func ErrInvalidRequest(msg string) error {
 	return &AppError{Code: CodeInvalidRequest, Message: msg}
 }
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+type PullRequestEventType string
+
+const (
+	PullRequestEventCreated         PullRequestEventType = "CREATED"
+	PullRequestEventAssigned        PullRequestEventType = "ASSIGNED"
+	PullRequestEventReassigned      PullRequestEventType = "REASSIGNED"
+	PullRequestEventMerged          PullRequestEventType = "MERGED"
+	PullRequestEventActivityChanged PullRequestEventType = "ACTIVITY_CHANGED"
+	PullRequestEventReviewed        PullRequestEventType = "REVIEWED"
+	PullRequestEventReady           PullRequestEventType = "READY"
+	PullRequestEventClosed          PullRequestEventType = "CLOSED"
+	PullRequestEventReopened        PullRequestEventType = "REOPENED"
+)
+
+// PullRequestEvent is a single entry of a pull request history.
+// ReviewerID is the assigned reviewer for ASSIGNED and REASSIGNED events and
+// the user whose activity changed for ACTIVITY_CHANGED. Decision is set only
+// for REVIEWED events.
+type PullRequestEvent struct {
+	EventID       int64                `db:"event_id" json:"event_id"`
+	PullRequestID string               `db:"pull_request_id" json:"pull_request_id"`
+	EventType     PullRequestEventType `db:"event_type" json:"event_type"`
+	ActorID       *string              `db:"actor_id" json:"actor_id,omitempty"`
+	ReviewerID    *string              `db:"reviewer_id" json:"reviewer_id,omitempty"`
+	OldReviewerID *string              `db:"old_reviewer_id" json:"old_reviewer_id,omitempty"`
+	IsActive      *bool                `db:"is_active" json:"is_active,omitempty"`
+	Decision      *ReviewDecision      `db:"decision" json:"decision,omitempty"`
+	CreatedAt     time.Time            `db:"created_at" json:"created_at"`
+}
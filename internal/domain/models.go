@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type TeamMember struct {
 	UserID   string `json:"user_id"`
@@ -12,6 +15,44 @@ type Team struct {
 	Members  []TeamMember `json:"members"`
 }
 
+type ReviewerStrategy string
+
+const (
+	ReviewerStrategyLeastLoaded ReviewerStrategy = "least_loaded"
+	ReviewerStrategyRoundRobin  ReviewerStrategy = "round_robin"
+	ReviewerStrategyRandom      ReviewerStrategy = "random"
+
+	DefaultReviewerStrategy = ReviewerStrategyLeastLoaded
+
+	DefaultRequiredReviewers = 2
+)
+
+func (s ReviewerStrategy) IsValid() bool {
+	switch s {
+	case ReviewerStrategyLeastLoaded, ReviewerStrategyRoundRobin, ReviewerStrategyRandom:
+		return true
+	default:
+		return false
+	}
+}
+
+type TeamSettings struct {
+	TeamName          string           `db:"team_name" json:"team_name"`
+	ReviewerStrategy  ReviewerStrategy `db:"reviewer_strategy" json:"reviewer_strategy"`
+	RequiredReviewers int              `db:"required_reviewers" json:"required_reviewers"`
+	RequiredApprovals int              `db:"required_approvals" json:"required_approvals"` // 0 — мерж без апрувов
+	FallbackTeams     []string         `db:"-" json:"fallback_teams"`                      // в порядке приоритета
+}
+
+// TeamSettingsUpdate describes a partial update, nil fields are left unchanged.
+// An empty non-nil FallbackTeams clears the fallback pool.
+type TeamSettingsUpdate struct {
+	ReviewerStrategy  *ReviewerStrategy
+	RequiredReviewers *int
+	RequiredApprovals *int
+	FallbackTeams     []string
+}
+
 type User struct {
 	UserID   string `db:"user_id" json:"user_id"`
 	Username string `db:"username" json:"username"`
@@ -21,22 +62,89 @@ type User struct {
 type PRStatus string
 
 const (
+	PRStatusDraft  PRStatus = "DRAFT"
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	PRStatusClosed PRStatus = "CLOSED"
 )
 
 func (s PRStatus) String() string {
 	return string(s)
 }
 
+type ReviewDecision string
+
+const (
+	ReviewDecisionApproved         ReviewDecision = "APPROVED"
+	ReviewDecisionChangesRequested ReviewDecision = "CHANGES_REQUESTED"
+	ReviewDecisionCommented        ReviewDecision = "COMMENTED"
+)
+
+func (d ReviewDecision) IsValid() bool {
+	switch d {
+	case ReviewDecisionApproved, ReviewDecisionChangesRequested, ReviewDecisionCommented:
+		return true
+	default:
+		return false
+	}
+}
+
+type Reviewer struct {
+	UserID     string          `db:"user_id" json:"user_id"`
+	TeamName   string          `db:"team_name" json:"team_name"` // команда, из которой взят ревьювер
+	AssignedAt *time.Time      `db:"assigned_at" json:"assigned_at,omitempty"`
+	Decision   *ReviewDecision `db:"decision" json:"decision,omitempty"` // nil, пока ревьювер не отписался
+	DecidedAt  *time.Time      `db:"decided_at" json:"decided_at,omitempty"`
+}
+
 type PullRequest struct {
 	PullRequestID     string     `db:"pull_request_id" json:"pull_request_id"`
 	PullRequestName   string     `db:"pull_request_name" json:"pull_request_name"`
 	AuthorID          string     `db:"author_id" json:"author_id"`
+	TeamName          string     `db:"team_name" json:"team_name"`
 	Status            PRStatus   `db:"status" json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
+	Reviewers         []Reviewer `json:"reviewers"`
 	CreatedAt         *time.Time `db:"created_at" json:"created_at,omitempty"`
 	MergedAt          *time.Time `db:"merged_at" json:"merged_at,omitempty"`
+	ClosedAt          *time.Time `db:"closed_at" json:"closed_at,omitempty"`
+}
+
+func ReviewerIDs(reviewers []Reviewer) []string {
+	ids := make([]string, 0, len(reviewers))
+	for _, r := range reviewers {
+		ids = append(ids, r.UserID)
+	}
+	return ids
+}
+
+const (
+	DefaultPullRequestPageSize = 20
+	MaxPullRequestPageSize     = 100
+)
+
+// PullRequestCursor points at the last pull request of the previous page.
+type PullRequestCursor struct {
+	CreatedAt     time.Time `json:"created_at"`
+	PullRequestID string    `json:"pull_request_id"`
+}
+
+type PullRequestFilter struct {
+	Status      PRStatus
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	After       *PullRequestCursor
+	Limit       int
+}
+
+type PullRequestPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -45,3 +153,39 @@ type PullRequestShort struct {
 	AuthorID        string   `db:"author_id" json:"author_id"`
 	Status          PRStatus `db:"status" json:"status"`
 }
+
+type Reassignment struct {
+	PullRequestID string `db:"pull_request_id" json:"pull_request_id"`
+	OldReviewerID string `db:"old_reviewer_id" json:"old_reviewer_id"`
+	NewReviewerID string `db:"new_reviewer_id" json:"new_reviewer_id"`
+	TeamName      string `db:"team_name" json:"team_name"`
+}
+
+type UnassignedReview struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+}
+
+type DeactivationReport struct {
+	TeamName         string             `json:"team_name"`
+	DeactivatedUsers []User             `json:"deactivated_users"`
+	Reassigned       []Reassignment     `json:"reassigned"`
+	WithoutCandidate []UnassignedReview `json:"without_candidate"`
+}
+
+type RemovalReport struct {
+	TeamName         string             `json:"team_name"`
+	RemovedUserIDs   []string           `json:"removed_user_ids"`
+	Reassigned       []Reassignment     `json:"reassigned"`
+	WithoutCandidate []UnassignedReview `json:"without_candidate"`
+}
+
+// IdempotencyRecord is the stored result of a PR creation made with an
+// Idempotency-Key, used to replay the response to identical retries. Keys
+// belong to the actor that sent them.
+type IdempotencyRecord struct {
+	ActorID     string          `db:"actor_id"`
+	Key         string          `db:"idempotency_key"`
+	RequestHash string          `db:"request_hash"`
+	Response    json.RawMessage `db:"response"`
+}
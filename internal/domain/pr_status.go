@@ -0,0 +1,27 @@
+package domain
+
+// prTransitions lists the statuses a pull request may move to from each
+// status. Reviewers are assigned on the way to OPEN and released on the way to
+// CLOSED; MERGED is final.
+var prTransitions = map[PRStatus][]PRStatus{
+	PRStatusDraft:  {PRStatusOpen, PRStatusClosed},
+	PRStatusOpen:   {PRStatusMerged, PRStatusClosed},
+	PRStatusClosed: {PRStatusOpen},
+	PRStatusMerged: {},
+}
+
+// IsValid reports whether s is a known pull request status.
+func (s PRStatus) IsValid() bool {
+	_, ok := prTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a pull request in status s may be moved to status to.
+func (s PRStatus) CanTransitionTo(to PRStatus) bool {
+	for _, allowed := range prTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
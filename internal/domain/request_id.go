@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID stores the id of the HTTP request being served, so that
+// every log line written on its behalf can be correlated.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id or an empty string outside of a request.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
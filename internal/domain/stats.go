@@ -0,0 +1,30 @@
+package domain
+
+type ReviewerStats struct {
+	UserID            string `db:"user_id" json:"user_id"`
+	Username          string `db:"username" json:"username"`
+	OpenAssignments   int    `db:"open_assignments" json:"open_assignments"`
+	MergedAssignments int    `db:"merged_assignments" json:"merged_assignments"`
+}
+
+type AuthorStats struct {
+	AuthorID           string `db:"author_id" json:"author_id"`
+	PullRequests       int    `db:"pull_requests" json:"pull_requests"`
+	OpenPullRequests   int    `db:"open_pull_requests" json:"open_pull_requests"`
+	MergedPullRequests int    `db:"merged_pull_requests" json:"merged_pull_requests"`
+}
+
+type TeamStats struct {
+	TeamName           string `db:"team_name" json:"team_name"`
+	PullRequests       int    `db:"pull_requests" json:"pull_requests"`
+	OpenPullRequests   int    `db:"open_pull_requests" json:"open_pull_requests"`
+	MergedPullRequests int    `db:"merged_pull_requests" json:"merged_pull_requests"`
+	Assignments        int    `db:"assignments" json:"assignments"`
+}
+
+type Stats struct {
+	Reviewers           []ReviewerStats `json:"reviewers"`
+	Authors             []AuthorStats   `json:"authors"`
+	Teams               []TeamStats     `json:"teams"`
+	AvgMergeTimeSeconds float64         `json:"avg_merge_time_seconds"`
+}
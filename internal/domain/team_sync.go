@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+)
+
+// MemberUpdate is a team member whose username or activity differs from the
+// stored one.
+type MemberUpdate struct {
+	User
+	PreviousUsername string `json:"previous_username"`
+	PreviousIsActive bool   `json:"previous_is_active"`
+}
+
+// TeamSyncDiff describes the changes that make a team match the synced payload.
+type TeamSyncDiff struct {
+	TeamName    string         `json:"team_name"`
+	DryRun      bool           `json:"dry_run"`
+	TeamCreated bool           `json:"team_created"`
+	Added       []User         `json:"added"`
+	Updated     []MemberUpdate `json:"updated"`
+	Removed     []User         `json:"removed"`
+}
+
+// DiffTeamMembers compares the current members of a team with the desired ones.
+// Members present in both are reported as updated only if their username or
+// activity changed. The result lists are ordered by user id.
+func DiffTeamMembers(teamName string, current, desired []User) *TeamSyncDiff {
+	diff := &TeamSyncDiff{
+		TeamName: teamName,
+		Added:    make([]User, 0),
+		Updated:  make([]MemberUpdate, 0),
+		Removed:  make([]User, 0),
+	}
+
+	byID := make(map[string]User, len(current))
+	for _, u := range current {
+		byID[u.UserID] = u
+	}
+
+	keep := make(map[string]bool, len(desired))
+	for _, u := range desired {
+		keep[u.UserID] = true
+
+		old, ok := byID[u.UserID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, u)
+		case old.Username != u.Username || old.IsActive != u.IsActive:
+			diff.Updated = append(diff.Updated, MemberUpdate{
+				User:             u,
+				PreviousUsername: old.Username,
+				PreviousIsActive: old.IsActive,
+			})
+		}
+	}
+
+	for _, u := range current {
+		if !keep[u.UserID] {
+			diff.Removed = append(diff.Removed, u)
+		}
+	}
+
+	slices.SortFunc(diff.Added, func(a, b User) int { return strings.Compare(a.UserID, b.UserID) })
+	slices.SortFunc(diff.Updated, func(a, b MemberUpdate) int { return strings.Compare(a.UserID, b.UserID) })
+	slices.SortFunc(diff.Removed, func(a, b User) int { return strings.Compare(a.UserID, b.UserID) })
+	return diff
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule names the check a field failed, so that clients can react to it
+// without parsing the message.
+type Rule string
+
+const (
+	RuleRequired     Rule = "required"
+	RuleMaxLength    Rule = "max_length"
+	RuleCharset      Rule = "charset"
+	RuleEnum         Rule = "enum"
+	RuleRange        Rule = "range"
+	RuleUnique       Rule = "unique"
+	RuleFormat       Rule = "format"
+	RuleType         Rule = "type"
+	RuleUnknownField Rule = "unknown_field"
+	RuleInvalid      Rule = "invalid"
+)
+
+const (
+	// MaxIDLength limits user, team and PR identifiers.
+	MaxIDLength = 64
+	// MaxNameLength limits free-form names like usernames and PR titles.
+	MaxNameLength = 255
+)
+
+// FieldError describes one failed check of a request field. Field is the
+// API name of the field, empty when the error concerns the whole request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    Rule   `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Validation collects the field errors of a request, so that all of them are
+// reported at once instead of one per round trip.
+type Validation struct {
+	details []FieldError
+}
+
+// Add records a failed check.
+func (v *Validation) Add(field string, rule Rule, message string) {
+	v.details = append(v.details, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Check records a failed check unless ok.
+func (v *Validation) Check(ok bool, field string, rule Rule, message string) {
+	if !ok {
+		v.Add(field, rule, message)
+	}
+}
+
+// Required checks that value is not empty.
+func (v *Validation) Required(field, value string) bool {
+	if value == "" {
+		v.Add(field, RuleRequired, field+" is empty")
+		return false
+	}
+	return true
+}
+
+// ID checks a new identifier: not empty, at most MaxIDLength characters of
+// letters, digits, '.', '_' and '-'. Identifiers of existing entities may
+// predate these rules, so lookups only check them with Required.
+func (v *Validation) ID(field, value string) {
+	if v.Required(field, value) {
+		v.OptionalID(field, value)
+	}
+}
+
+// OptionalID checks a new identifier that may be omitted.
+func (v *Validation) OptionalID(field, value string) {
+	switch {
+	case value == "":
+	case utf8.RuneCountInString(value) > MaxIDLength:
+		v.Add(field, RuleMaxLength, fmt.Sprintf("%s must be at most %d characters", field, MaxIDLength))
+	case !isIDCharset(value):
+		v.Add(field, RuleCharset, field+" may contain only letters, digits, '.', '_' and '-'")
+	}
+}
+
+// Name checks a required free-form name: not empty and at most MaxNameLength
+// characters.
+func (v *Validation) Name(field, value string) {
+	if v.Required(field, value) && utf8.RuneCountInString(value) > MaxNameLength {
+		v.Add(field, RuleMaxLength, fmt.Sprintf("%s must be at most %d characters", field, MaxNameLength))
+	}
+}
+
+// Err returns an INVALID_REQUEST error with the collected details, or nil if
+// every check passed.
+func (v *Validation) Err() error {
+	if len(v.details) == 0 {
+		return nil
+	}
+	return ErrValidation(v.details...)
+}
+
+// IsValidID reports whether value would pass ID.
+func IsValidID(value string) bool {
+	return value != "" && utf8.RuneCountInString(value) <= MaxIDLength && isIDCharset(value)
+}
+
+func isIDCharset(value string) bool {
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ErrValidation builds an INVALID_REQUEST error from field errors; its message
+// joins the messages of the details.
+func ErrValidation(details ...FieldError) error {
+	messages := make([]string, 0, len(details))
+	for _, detail := range details {
+		messages = append(messages, detail.Message)
+	}
+	return &AppError{Code: CodeInvalidRequest, Message: strings.Join(messages, "; "), Details: details}
+}
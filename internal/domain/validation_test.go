@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidation(t *testing.T) {
+	t.Run("no errors", func(t *testing.T) {
+		var v domain.Validation
+		v.ID("user_id", "u-1.test_2")
+		v.OptionalID("team_name", "")
+		v.Name("username", "Алиса")
+		v.Check(true, "limit", domain.RuleRange, "limit is out of range")
+
+		assert.NoError(t, v.Err())
+	})
+
+	t.Run("collect every failure", func(t *testing.T) {
+		var v domain.Validation
+		v.ID("user_id", "")
+		v.ID("team_name", "back end")
+		v.OptionalID("author_id", strings.Repeat("a", domain.MaxIDLength+1))
+		v.Name("username", strings.Repeat("я", domain.MaxNameLength+1))
+		v.Check(false, "limit", domain.RuleRange, "limit is out of range")
+
+		var appErr *domain.AppError
+		require.ErrorAs(t, v.Err(), &appErr)
+		assert.Equal(t, domain.CodeInvalidRequest, appErr.Code)
+
+		rules := make(map[string]domain.Rule, len(appErr.Details))
+		for _, detail := range appErr.Details {
+			rules[detail.Field] = detail.Rule
+		}
+		assert.Equal(t, map[string]domain.Rule{
+			"user_id":   domain.RuleRequired,
+			"team_name": domain.RuleCharset,
+			"author_id": domain.RuleMaxLength,
+			"username":  domain.RuleMaxLength,
+			"limit":     domain.RuleRange,
+		}, rules)
+		assert.Equal(t, "user_id is empty", appErr.Details[0].Message)
+		assert.Contains(t, appErr.Message, "user_id is empty; team_name may contain only")
+	})
+
+	t.Run("names are limited in characters, not bytes", func(t *testing.T) {
+		var v domain.Validation
+		v.Name("username", strings.Repeat("я", domain.MaxNameLength))
+		assert.NoError(t, v.Err())
+	})
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventPRCreated    WebhookEvent = "pr.created"
+	WebhookEventPRReassigned WebhookEvent = "pr.reassigned"
+	WebhookEventPRMerged     WebhookEvent = "pr.merged"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "DEAD"
+)
+
+// Webhook is an endpoint that receives lifecycle events of the team's pull requests.
+// Secret is only returned once, when the webhook is registered.
+type Webhook struct {
+	WebhookID int64     `db:"webhook_id" json:"webhook_id"`
+	TeamName  string    `db:"team_name" json:"team_name"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// WebhookDelivery is an outbox entry claimed for sending.
+type WebhookDelivery struct {
+	DeliveryID int64           `db:"delivery_id"`
+	WebhookID  int64           `db:"webhook_id"`
+	URL        string          `db:"url"`
+	Secret     string          `db:"secret"`
+	EventType  WebhookEvent    `db:"event_type"`
+	Payload    json.RawMessage `db:"payload"`
+	Attempts   int             `db:"attempts"`
+}
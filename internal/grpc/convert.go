@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/grpc/pb"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func userToPB(user domain.User) *pb.User {
+	return &pb.User{
+		UserId:   user.UserID,
+		Username: user.Username,
+		IsActive: user.IsActive,
+	}
+}
+
+func usersToPB(users []domain.User) []*pb.User {
+	result := make([]*pb.User, 0, len(users))
+	for _, user := range users {
+		result = append(result, userToPB(user))
+	}
+	return result
+}
+
+func usersFromPB(users []*pb.User) []domain.User {
+	result := make([]domain.User, 0, len(users))
+	for _, user := range users {
+		result = append(result, domain.User{
+			UserID:   user.GetUserId(),
+			Username: user.GetUsername(),
+			IsActive: user.GetIsActive(),
+		})
+	}
+	return result
+}
+
+func pullRequestToPB(pr *domain.PullRequest) *pb.PullRequest {
+	reviewers := make([]*pb.Reviewer, 0, len(pr.Reviewers))
+	for _, reviewer := range pr.Reviewers {
+		decision := ""
+		if reviewer.Decision != nil {
+			decision = string(*reviewer.Decision)
+		}
+		reviewers = append(reviewers, &pb.Reviewer{
+			UserId:   reviewer.UserID,
+			TeamName: reviewer.TeamName,
+			Decision: decision,
+		})
+	}
+
+	return &pb.PullRequest{
+		PullRequestId:   pr.PullRequestID,
+		PullRequestName: pr.PullRequestName,
+		AuthorId:        pr.AuthorID,
+		TeamName:        pr.TeamName,
+		Status:          string(pr.Status),
+		Reviewers:       reviewers,
+		CreatedAt:       timestampToPB(pr.CreatedAt),
+		MergedAt:        timestampToPB(pr.MergedAt),
+		ClosedAt:        timestampToPB(pr.ClosedAt),
+	}
+}
+
+func timestampToPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/requestid"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/theartofdevel/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+var requestIDKey = strings.ToLower(requestid.Header)
+
+const (
+	// errorDomain is the ErrorInfo domain of the service error codes.
+	errorDomain = "pr_service"
+)
+
+// requestInterceptor is the gRPC counterpart of the HTTP request logging
+// middleware: it takes the request id from the x-request-id metadata or
+// generates one, returns it in the response header, puts a logger carrying it
+// into the context and logs the method, status code and latency of each call.
+func requestInterceptor(logger *logging.Logger) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		ctx, requestLogger, requestID := requestid.Start(ctx, logger, firstMetadata(ctx, requestIDKey))
+		_ = grpclib.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+		resp, err := handler(ctx, req)
+
+		requestLogger.Info("grpc request",
+			logging.StringAttr("method", info.FullMethod),
+			logging.StringAttr("code", status.Code(err).String()),
+			logging.IntAttr("latency_ms", int(time.Since(start).Milliseconds())),
+		)
+		return resp, err
+	}
+}
+
+// authInterceptor authenticates the bearer token from the authorization
+// metadata and checks it against the role the method requires. Methods
+// missing from methodRoles are refused.
+func authInterceptor(authenticator auth.Authenticator) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+		required, ok := methodRoles[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.Unimplemented, "unknown method")
+		}
+
+		token, ok := strings.CutPrefix(firstMetadata(ctx, "authorization"), "Bearer ")
+		if !ok || token == "" {
+			return nil, toStatus(domain.ErrUnauthorized())
+		}
+
+		principal, err := authenticator.Authenticate(token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				err = domain.ErrUnauthorized()
+			}
+			return nil, toStatus(err)
+		}
+
+		ctx = domain.ContextWithActor(ctx, principal.Subject)
+		if principal.Role == auth.RoleAdmin {
+			ctx = domain.ContextWithAdmin(ctx)
+		}
+		ctx = logging.ContextWithLogger(ctx, logging.L(ctx).With(
+			logging.StringAttr("actor", principal.Subject),
+			logging.StringAttr("role", string(principal.Role)),
+		))
+
+		if !principal.Role.Allows(required) {
+			return nil, toStatus(domain.ErrForbidden())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// errorInterceptor turns errors returned by the service into gRPC statuses.
+func errorInterceptor(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+// toStatus maps an AppError to the matching gRPC code and attaches its code
+// as the ErrorInfo reason, so that clients can tell e.g. PR_MERGED from
+// NOT_ASSIGNED; field errors go into a BadRequest detail. Any other error
+// becomes Internal without leaking details.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: string(appErr.Code),
+		Domain: errorDomain,
+	}}
+	if len(appErr.Details) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, detail := range appErr.Details {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       detail.Field,
+				Description: detail.Message,
+				Reason:      strings.ToUpper(string(detail.Rule)),
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	st := status.New(codeFromAppCode(appErr.Code), appErr.Message)
+	detailed, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+func codeFromAppCode(code domain.ErrorCode) codes.Code {
+	switch code {
+	case domain.CodeInvalidRequest:
+		return codes.InvalidArgument
+	case domain.CodeNotFound:
+		return codes.NotFound
+	case domain.CodeTeamExists, domain.CodePRExists, domain.CodeWebhookExists:
+		return codes.AlreadyExists
+	case domain.CodePRMerged, domain.CodeNotAssigned, domain.CodeNoCandidate, domain.CodeNotApproved,
+		domain.CodeInvalidTransition, domain.CodeIdempotencyKeyReused:
+		return codes.FailedPrecondition
+	case domain.CodeUnauthorized:
+		return codes.Unauthenticated
+	case domain.CodeForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,1207 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: pr_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	IsActive      bool                   `protobuf:"varint,3,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_pr_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+type Reviewer struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// команда, из которой взят ревьювер
+	TeamName string `protobuf:"bytes,2,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	// пусто, пока ревьювер не оставил решение
+	Decision      string `protobuf:"bytes,3,opt,name=decision,proto3" json:"decision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reviewer) Reset() {
+	*x = Reviewer{}
+	mi := &file_pr_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reviewer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reviewer) ProtoMessage() {}
+
+func (x *Reviewer) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reviewer.ProtoReflect.Descriptor instead.
+func (*Reviewer) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Reviewer) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Reviewer) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *Reviewer) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+type PullRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId   string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	PullRequestName string                 `protobuf:"bytes,2,opt,name=pull_request_name,json=pullRequestName,proto3" json:"pull_request_name,omitempty"`
+	AuthorId        string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	TeamName        string                 `protobuf:"bytes,4,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	// DRAFT, OPEN, MERGED или CLOSED
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Reviewers     []*Reviewer            `protobuf:"bytes,6,rep,name=reviewers,proto3" json:"reviewers,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MergedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=merged_at,json=mergedAt,proto3" json:"merged_at,omitempty"`
+	ClosedAt      *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullRequest) Reset() {
+	*x = PullRequest{}
+	mi := &file_pr_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullRequest) ProtoMessage() {}
+
+func (x *PullRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullRequest.ProtoReflect.Descriptor instead.
+func (*PullRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PullRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *PullRequest) GetPullRequestName() string {
+	if x != nil {
+		return x.PullRequestName
+	}
+	return ""
+}
+
+func (x *PullRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *PullRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *PullRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PullRequest) GetReviewers() []*Reviewer {
+	if x != nil {
+		return x.Reviewers
+	}
+	return nil
+}
+
+func (x *PullRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PullRequest) GetMergedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MergedAt
+	}
+	return nil
+}
+
+func (x *PullRequest) GetClosedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return nil
+}
+
+type PullRequestShort struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId   string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	PullRequestName string                 `protobuf:"bytes,2,opt,name=pull_request_name,json=pullRequestName,proto3" json:"pull_request_name,omitempty"`
+	AuthorId        string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Status          string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PullRequestShort) Reset() {
+	*x = PullRequestShort{}
+	mi := &file_pr_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullRequestShort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullRequestShort) ProtoMessage() {}
+
+func (x *PullRequestShort) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullRequestShort.ProtoReflect.Descriptor instead.
+func (*PullRequestShort) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PullRequestShort) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *PullRequestShort) GetPullRequestName() string {
+	if x != nil {
+		return x.PullRequestName
+	}
+	return ""
+}
+
+func (x *PullRequestShort) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *PullRequestShort) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type CreateTeamRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TeamName string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	Members  []*User                `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	// пустые значения заменяются настройками по умолчанию
+	ReviewerStrategy  string   `protobuf:"bytes,3,opt,name=reviewer_strategy,json=reviewerStrategy,proto3" json:"reviewer_strategy,omitempty"`
+	RequiredReviewers int32    `protobuf:"varint,4,opt,name=required_reviewers,json=requiredReviewers,proto3" json:"required_reviewers,omitempty"`
+	RequiredApprovals int32    `protobuf:"varint,5,opt,name=required_approvals,json=requiredApprovals,proto3" json:"required_approvals,omitempty"`
+	FallbackTeams     []string `protobuf:"bytes,6,rep,name=fallback_teams,json=fallbackTeams,proto3" json:"fallback_teams,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CreateTeamRequest) Reset() {
+	*x = CreateTeamRequest{}
+	mi := &file_pr_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTeamRequest) ProtoMessage() {}
+
+func (x *CreateTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTeamRequest.ProtoReflect.Descriptor instead.
+func (*CreateTeamRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateTeamRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *CreateTeamRequest) GetMembers() []*User {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *CreateTeamRequest) GetReviewerStrategy() string {
+	if x != nil {
+		return x.ReviewerStrategy
+	}
+	return ""
+}
+
+func (x *CreateTeamRequest) GetRequiredReviewers() int32 {
+	if x != nil {
+		return x.RequiredReviewers
+	}
+	return 0
+}
+
+func (x *CreateTeamRequest) GetRequiredApprovals() int32 {
+	if x != nil {
+		return x.RequiredApprovals
+	}
+	return 0
+}
+
+func (x *CreateTeamRequest) GetFallbackTeams() []string {
+	if x != nil {
+		return x.FallbackTeams
+	}
+	return nil
+}
+
+type CreateTeamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TeamName      string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	Members       []*User                `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTeamResponse) Reset() {
+	*x = CreateTeamResponse{}
+	mi := &file_pr_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTeamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTeamResponse) ProtoMessage() {}
+
+func (x *CreateTeamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTeamResponse.ProtoReflect.Descriptor instead.
+func (*CreateTeamResponse) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateTeamResponse) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *CreateTeamResponse) GetMembers() []*User {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type GetTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TeamName      string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTeamRequest) Reset() {
+	*x = GetTeamRequest{}
+	mi := &file_pr_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTeamRequest) ProtoMessage() {}
+
+func (x *GetTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTeamRequest.ProtoReflect.Descriptor instead.
+func (*GetTeamRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTeamRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+type GetTeamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TeamName      string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	Members       []*User                `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTeamResponse) Reset() {
+	*x = GetTeamResponse{}
+	mi := &file_pr_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTeamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTeamResponse) ProtoMessage() {}
+
+func (x *GetTeamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTeamResponse.ProtoReflect.Descriptor instead.
+func (*GetTeamResponse) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTeamResponse) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *GetTeamResponse) GetMembers() []*User {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type SetIsActiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IsActive      bool                   `protobuf:"varint,2,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetIsActiveRequest) Reset() {
+	*x = SetIsActiveRequest{}
+	mi := &file_pr_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetIsActiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetIsActiveRequest) ProtoMessage() {}
+
+func (x *SetIsActiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetIsActiveRequest.ProtoReflect.Descriptor instead.
+func (*SetIsActiveRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetIsActiveRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetIsActiveRequest) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+type SetIsActiveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	TeamNames     []string               `protobuf:"bytes,2,rep,name=team_names,json=teamNames,proto3" json:"team_names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetIsActiveResponse) Reset() {
+	*x = SetIsActiveResponse{}
+	mi := &file_pr_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetIsActiveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetIsActiveResponse) ProtoMessage() {}
+
+func (x *SetIsActiveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetIsActiveResponse.ProtoReflect.Descriptor instead.
+func (*SetIsActiveResponse) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetIsActiveResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *SetIsActiveResponse) GetTeamNames() []string {
+	if x != nil {
+		return x.TeamNames
+	}
+	return nil
+}
+
+type GetReviewRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReviewRequest) Reset() {
+	*x = GetReviewRequest{}
+	mi := &file_pr_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReviewRequest) ProtoMessage() {}
+
+func (x *GetReviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReviewRequest.ProtoReflect.Descriptor instead.
+func (*GetReviewRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetReviewRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetReviewResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PullRequests  []*PullRequestShort    `protobuf:"bytes,2,rep,name=pull_requests,json=pullRequests,proto3" json:"pull_requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReviewResponse) Reset() {
+	*x = GetReviewResponse{}
+	mi := &file_pr_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReviewResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReviewResponse) ProtoMessage() {}
+
+func (x *GetReviewResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReviewResponse.ProtoReflect.Descriptor instead.
+func (*GetReviewResponse) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetReviewResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetReviewResponse) GetPullRequests() []*PullRequestShort {
+	if x != nil {
+		return x.PullRequests
+	}
+	return nil
+}
+
+type CreatePullRequestRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId   string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	PullRequestName string                 `protobuf:"bytes,2,opt,name=pull_request_name,json=pullRequestName,proto3" json:"pull_request_name,omitempty"`
+	AuthorId        string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// обязателен, если автор состоит в нескольких командах
+	TeamName string `protobuf:"bytes,4,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	Draft    bool   `protobuf:"varint,5,opt,name=draft,proto3" json:"draft,omitempty"`
+	// повтор с тем же ключом и телом возвращает исходный PR
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreatePullRequestRequest) Reset() {
+	*x = CreatePullRequestRequest{}
+	mi := &file_pr_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePullRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePullRequestRequest) ProtoMessage() {}
+
+func (x *CreatePullRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePullRequestRequest.ProtoReflect.Descriptor instead.
+func (*CreatePullRequestRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreatePullRequestRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetPullRequestName() string {
+	if x != nil {
+		return x.PullRequestName
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetDraft() bool {
+	if x != nil {
+		return x.Draft
+	}
+	return false
+}
+
+func (x *CreatePullRequestRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type MergePullRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergePullRequestRequest) Reset() {
+	*x = MergePullRequestRequest{}
+	mi := &file_pr_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergePullRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergePullRequestRequest) ProtoMessage() {}
+
+func (x *MergePullRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergePullRequestRequest.ProtoReflect.Descriptor instead.
+func (*MergePullRequestRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MergePullRequestRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+type PullRequestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pr            *PullRequest           `protobuf:"bytes,1,opt,name=pr,proto3" json:"pr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullRequestResponse) Reset() {
+	*x = PullRequestResponse{}
+	mi := &file_pr_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullRequestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullRequestResponse) ProtoMessage() {}
+
+func (x *PullRequestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullRequestResponse.ProtoReflect.Descriptor instead.
+func (*PullRequestResponse) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PullRequestResponse) GetPr() *PullRequest {
+	if x != nil {
+		return x.Pr
+	}
+	return nil
+}
+
+type ReAssignRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	OldUserId     string                 `protobuf:"bytes,2,opt,name=old_user_id,json=oldUserId,proto3" json:"old_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReAssignRequest) Reset() {
+	*x = ReAssignRequest{}
+	mi := &file_pr_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReAssignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReAssignRequest) ProtoMessage() {}
+
+func (x *ReAssignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReAssignRequest.ProtoReflect.Descriptor instead.
+func (*ReAssignRequest) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReAssignRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *ReAssignRequest) GetOldUserId() string {
+	if x != nil {
+		return x.OldUserId
+	}
+	return ""
+}
+
+type ReAssignResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pr            *PullRequest           `protobuf:"bytes,1,opt,name=pr,proto3" json:"pr,omitempty"`
+	ReplacedBy    string                 `protobuf:"bytes,2,opt,name=replaced_by,json=replacedBy,proto3" json:"replaced_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReAssignResponse) Reset() {
+	*x = ReAssignResponse{}
+	mi := &file_pr_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReAssignResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReAssignResponse) ProtoMessage() {}
+
+func (x *ReAssignResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pr_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReAssignResponse.ProtoReflect.Descriptor instead.
+func (*ReAssignResponse) Descriptor() ([]byte, []int) {
+	return file_pr_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReAssignResponse) GetPr() *PullRequest {
+	if x != nil {
+		return x.Pr
+	}
+	return nil
+}
+
+func (x *ReAssignResponse) GetReplacedBy() string {
+	if x != nil {
+		return x.ReplacedBy
+	}
+	return ""
+}
+
+var File_pr_service_proto protoreflect.FileDescriptor
+
+const file_pr_service_proto_rawDesc = "" +
+	"\n" +
+	"\x10pr_service.proto\x12\rpr_service.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"X\n" +
+	"\x04User\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1b\n" +
+	"\tis_active\x18\x03 \x01(\bR\bisActive\"\\\n" +
+	"\bReviewer\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tteam_name\x18\x02 \x01(\tR\bteamName\x12\x1a\n" +
+	"\bdecision\x18\x03 \x01(\tR\bdecision\"\x97\x03\n" +
+	"\vPullRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12*\n" +
+	"\x11pull_request_name\x18\x02 \x01(\tR\x0fpullRequestName\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x1b\n" +
+	"\tteam_name\x18\x04 \x01(\tR\bteamName\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x125\n" +
+	"\treviewers\x18\x06 \x03(\v2\x17.pr_service.v1.ReviewerR\treviewers\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tmerged_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bmergedAt\x127\n" +
+	"\tclosed_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\bclosedAt\"\x9b\x01\n" +
+	"\x10PullRequestShort\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12*\n" +
+	"\x11pull_request_name\x18\x02 \x01(\tR\x0fpullRequestName\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\"\x91\x02\n" +
+	"\x11CreateTeamRequest\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\x12-\n" +
+	"\amembers\x18\x02 \x03(\v2\x13.pr_service.v1.UserR\amembers\x12+\n" +
+	"\x11reviewer_strategy\x18\x03 \x01(\tR\x10reviewerStrategy\x12-\n" +
+	"\x12required_reviewers\x18\x04 \x01(\x05R\x11requiredReviewers\x12-\n" +
+	"\x12required_approvals\x18\x05 \x01(\x05R\x11requiredApprovals\x12%\n" +
+	"\x0efallback_teams\x18\x06 \x03(\tR\rfallbackTeams\"`\n" +
+	"\x12CreateTeamResponse\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\x12-\n" +
+	"\amembers\x18\x02 \x03(\v2\x13.pr_service.v1.UserR\amembers\"-\n" +
+	"\x0eGetTeamRequest\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\"]\n" +
+	"\x0fGetTeamResponse\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\x12-\n" +
+	"\amembers\x18\x02 \x03(\v2\x13.pr_service.v1.UserR\amembers\"J\n" +
+	"\x12SetIsActiveRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tis_active\x18\x02 \x01(\bR\bisActive\"]\n" +
+	"\x13SetIsActiveResponse\x12'\n" +
+	"\x04user\x18\x01 \x01(\v2\x13.pr_service.v1.UserR\x04user\x12\x1d\n" +
+	"\n" +
+	"team_names\x18\x02 \x03(\tR\tteamNames\"+\n" +
+	"\x10GetReviewRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"r\n" +
+	"\x11GetReviewResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12D\n" +
+	"\rpull_requests\x18\x02 \x03(\v2\x1f.pr_service.v1.PullRequestShortR\fpullRequests\"\xe7\x01\n" +
+	"\x18CreatePullRequestRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12*\n" +
+	"\x11pull_request_name\x18\x02 \x01(\tR\x0fpullRequestName\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x1b\n" +
+	"\tteam_name\x18\x04 \x01(\tR\bteamName\x12\x14\n" +
+	"\x05draft\x18\x05 \x01(\bR\x05draft\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\"A\n" +
+	"\x17MergePullRequestRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\"A\n" +
+	"\x13PullRequestResponse\x12*\n" +
+	"\x02pr\x18\x01 \x01(\v2\x1a.pr_service.v1.PullRequestR\x02pr\"Y\n" +
+	"\x0fReAssignRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12\x1e\n" +
+	"\vold_user_id\x18\x02 \x01(\tR\toldUserId\"_\n" +
+	"\x10ReAssignResponse\x12*\n" +
+	"\x02pr\x18\x01 \x01(\v2\x1a.pr_service.v1.PullRequestR\x02pr\x12\x1f\n" +
+	"\vreplaced_by\x18\x02 \x01(\tR\n" +
+	"replacedBy2\xdd\x04\n" +
+	"\tPRService\x12Q\n" +
+	"\n" +
+	"CreateTeam\x12 .pr_service.v1.CreateTeamRequest\x1a!.pr_service.v1.CreateTeamResponse\x12H\n" +
+	"\aGetTeam\x12\x1d.pr_service.v1.GetTeamRequest\x1a\x1e.pr_service.v1.GetTeamResponse\x12T\n" +
+	"\vSetIsActive\x12!.pr_service.v1.SetIsActiveRequest\x1a\".pr_service.v1.SetIsActiveResponse\x12N\n" +
+	"\tGetReview\x12\x1f.pr_service.v1.GetReviewRequest\x1a .pr_service.v1.GetReviewResponse\x12`\n" +
+	"\x11CreatePullRequest\x12'.pr_service.v1.CreatePullRequestRequest\x1a\".pr_service.v1.PullRequestResponse\x12^\n" +
+	"\x10MergePullRequest\x12&.pr_service.v1.MergePullRequestRequest\x1a\".pr_service.v1.PullRequestResponse\x12K\n" +
+	"\bReAssign\x12\x1e.pr_service.v1.ReAssignRequest\x1a\x1f.pr_service.v1.ReAssignResponseB7Z5ReilBleem13/pull_requests_service/internal/grpc/pb;pbb\x06proto3"
+
+var (
+	file_pr_service_proto_rawDescOnce sync.Once
+	file_pr_service_proto_rawDescData []byte
+)
+
+func file_pr_service_proto_rawDescGZIP() []byte {
+	file_pr_service_proto_rawDescOnce.Do(func() {
+		file_pr_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pr_service_proto_rawDesc), len(file_pr_service_proto_rawDesc)))
+	})
+	return file_pr_service_proto_rawDescData
+}
+
+var file_pr_service_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_pr_service_proto_goTypes = []any{
+	(*User)(nil),                     // 0: pr_service.v1.User
+	(*Reviewer)(nil),                 // 1: pr_service.v1.Reviewer
+	(*PullRequest)(nil),              // 2: pr_service.v1.PullRequest
+	(*PullRequestShort)(nil),         // 3: pr_service.v1.PullRequestShort
+	(*CreateTeamRequest)(nil),        // 4: pr_service.v1.CreateTeamRequest
+	(*CreateTeamResponse)(nil),       // 5: pr_service.v1.CreateTeamResponse
+	(*GetTeamRequest)(nil),           // 6: pr_service.v1.GetTeamRequest
+	(*GetTeamResponse)(nil),          // 7: pr_service.v1.GetTeamResponse
+	(*SetIsActiveRequest)(nil),       // 8: pr_service.v1.SetIsActiveRequest
+	(*SetIsActiveResponse)(nil),      // 9: pr_service.v1.SetIsActiveResponse
+	(*GetReviewRequest)(nil),         // 10: pr_service.v1.GetReviewRequest
+	(*GetReviewResponse)(nil),        // 11: pr_service.v1.GetReviewResponse
+	(*CreatePullRequestRequest)(nil), // 12: pr_service.v1.CreatePullRequestRequest
+	(*MergePullRequestRequest)(nil),  // 13: pr_service.v1.MergePullRequestRequest
+	(*PullRequestResponse)(nil),      // 14: pr_service.v1.PullRequestResponse
+	(*ReAssignRequest)(nil),          // 15: pr_service.v1.ReAssignRequest
+	(*ReAssignResponse)(nil),         // 16: pr_service.v1.ReAssignResponse
+	(*timestamppb.Timestamp)(nil),    // 17: google.protobuf.Timestamp
+}
+var file_pr_service_proto_depIdxs = []int32{
+	1,  // 0: pr_service.v1.PullRequest.reviewers:type_name -> pr_service.v1.Reviewer
+	17, // 1: pr_service.v1.PullRequest.created_at:type_name -> google.protobuf.Timestamp
+	17, // 2: pr_service.v1.PullRequest.merged_at:type_name -> google.protobuf.Timestamp
+	17, // 3: pr_service.v1.PullRequest.closed_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: pr_service.v1.CreateTeamRequest.members:type_name -> pr_service.v1.User
+	0,  // 5: pr_service.v1.CreateTeamResponse.members:type_name -> pr_service.v1.User
+	0,  // 6: pr_service.v1.GetTeamResponse.members:type_name -> pr_service.v1.User
+	0,  // 7: pr_service.v1.SetIsActiveResponse.user:type_name -> pr_service.v1.User
+	3,  // 8: pr_service.v1.GetReviewResponse.pull_requests:type_name -> pr_service.v1.PullRequestShort
+	2,  // 9: pr_service.v1.PullRequestResponse.pr:type_name -> pr_service.v1.PullRequest
+	2,  // 10: pr_service.v1.ReAssignResponse.pr:type_name -> pr_service.v1.PullRequest
+	4,  // 11: pr_service.v1.PRService.CreateTeam:input_type -> pr_service.v1.CreateTeamRequest
+	6,  // 12: pr_service.v1.PRService.GetTeam:input_type -> pr_service.v1.GetTeamRequest
+	8,  // 13: pr_service.v1.PRService.SetIsActive:input_type -> pr_service.v1.SetIsActiveRequest
+	10, // 14: pr_service.v1.PRService.GetReview:input_type -> pr_service.v1.GetReviewRequest
+	12, // 15: pr_service.v1.PRService.CreatePullRequest:input_type -> pr_service.v1.CreatePullRequestRequest
+	13, // 16: pr_service.v1.PRService.MergePullRequest:input_type -> pr_service.v1.MergePullRequestRequest
+	15, // 17: pr_service.v1.PRService.ReAssign:input_type -> pr_service.v1.ReAssignRequest
+	5,  // 18: pr_service.v1.PRService.CreateTeam:output_type -> pr_service.v1.CreateTeamResponse
+	7,  // 19: pr_service.v1.PRService.GetTeam:output_type -> pr_service.v1.GetTeamResponse
+	9,  // 20: pr_service.v1.PRService.SetIsActive:output_type -> pr_service.v1.SetIsActiveResponse
+	11, // 21: pr_service.v1.PRService.GetReview:output_type -> pr_service.v1.GetReviewResponse
+	14, // 22: pr_service.v1.PRService.CreatePullRequest:output_type -> pr_service.v1.PullRequestResponse
+	14, // 23: pr_service.v1.PRService.MergePullRequest:output_type -> pr_service.v1.PullRequestResponse
+	16, // 24: pr_service.v1.PRService.ReAssign:output_type -> pr_service.v1.ReAssignResponse
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_pr_service_proto_init() }
+func file_pr_service_proto_init() {
+	if File_pr_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pr_service_proto_rawDesc), len(file_pr_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pr_service_proto_goTypes,
+		DependencyIndexes: file_pr_service_proto_depIdxs,
+		MessageInfos:      file_pr_service_proto_msgTypes,
+	}.Build()
+	File_pr_service_proto = out.File
+	file_pr_service_proto_goTypes = nil
+	file_pr_service_proto_depIdxs = nil
+}
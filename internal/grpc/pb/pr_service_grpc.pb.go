@@ -0,0 +1,355 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pr_service.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PRService_CreateTeam_FullMethodName        = "/pr_service.v1.PRService/CreateTeam"
+	PRService_GetTeam_FullMethodName           = "/pr_service.v1.PRService/GetTeam"
+	PRService_SetIsActive_FullMethodName       = "/pr_service.v1.PRService/SetIsActive"
+	PRService_GetReview_FullMethodName         = "/pr_service.v1.PRService/GetReview"
+	PRService_CreatePullRequest_FullMethodName = "/pr_service.v1.PRService/CreatePullRequest"
+	PRService_MergePullRequest_FullMethodName  = "/pr_service.v1.PRService/MergePullRequest"
+	PRService_ReAssign_FullMethodName          = "/pr_service.v1.PRService/ReAssign"
+)
+
+// PRServiceClient is the client API for PRService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PRService exposes the same operations as the REST API. Errors carry the
+// service error code (NOT_FOUND, PR_MERGED, ...) as the ErrorInfo reason.
+type PRServiceClient interface {
+	CreateTeam(ctx context.Context, in *CreateTeamRequest, opts ...grpc.CallOption) (*CreateTeamResponse, error)
+	GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*GetTeamResponse, error)
+	SetIsActive(ctx context.Context, in *SetIsActiveRequest, opts ...grpc.CallOption) (*SetIsActiveResponse, error)
+	GetReview(ctx context.Context, in *GetReviewRequest, opts ...grpc.CallOption) (*GetReviewResponse, error)
+	CreatePullRequest(ctx context.Context, in *CreatePullRequestRequest, opts ...grpc.CallOption) (*PullRequestResponse, error)
+	MergePullRequest(ctx context.Context, in *MergePullRequestRequest, opts ...grpc.CallOption) (*PullRequestResponse, error)
+	ReAssign(ctx context.Context, in *ReAssignRequest, opts ...grpc.CallOption) (*ReAssignResponse, error)
+}
+
+type pRServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPRServiceClient(cc grpc.ClientConnInterface) PRServiceClient {
+	return &pRServiceClient{cc}
+}
+
+func (c *pRServiceClient) CreateTeam(ctx context.Context, in *CreateTeamRequest, opts ...grpc.CallOption) (*CreateTeamResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTeamResponse)
+	err := c.cc.Invoke(ctx, PRService_CreateTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pRServiceClient) GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*GetTeamResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTeamResponse)
+	err := c.cc.Invoke(ctx, PRService_GetTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pRServiceClient) SetIsActive(ctx context.Context, in *SetIsActiveRequest, opts ...grpc.CallOption) (*SetIsActiveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetIsActiveResponse)
+	err := c.cc.Invoke(ctx, PRService_SetIsActive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pRServiceClient) GetReview(ctx context.Context, in *GetReviewRequest, opts ...grpc.CallOption) (*GetReviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetReviewResponse)
+	err := c.cc.Invoke(ctx, PRService_GetReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pRServiceClient) CreatePullRequest(ctx context.Context, in *CreatePullRequestRequest, opts ...grpc.CallOption) (*PullRequestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequestResponse)
+	err := c.cc.Invoke(ctx, PRService_CreatePullRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pRServiceClient) MergePullRequest(ctx context.Context, in *MergePullRequestRequest, opts ...grpc.CallOption) (*PullRequestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequestResponse)
+	err := c.cc.Invoke(ctx, PRService_MergePullRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pRServiceClient) ReAssign(ctx context.Context, in *ReAssignRequest, opts ...grpc.CallOption) (*ReAssignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReAssignResponse)
+	err := c.cc.Invoke(ctx, PRService_ReAssign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PRServiceServer is the server API for PRService service.
+// All implementations must embed UnimplementedPRServiceServer
+// for forward compatibility.
+//
+// PRService exposes the same operations as the REST API. Errors carry the
+// service error code (NOT_FOUND, PR_MERGED, ...) as the ErrorInfo reason.
+type PRServiceServer interface {
+	CreateTeam(context.Context, *CreateTeamRequest) (*CreateTeamResponse, error)
+	GetTeam(context.Context, *GetTeamRequest) (*GetTeamResponse, error)
+	SetIsActive(context.Context, *SetIsActiveRequest) (*SetIsActiveResponse, error)
+	GetReview(context.Context, *GetReviewRequest) (*GetReviewResponse, error)
+	CreatePullRequest(context.Context, *CreatePullRequestRequest) (*PullRequestResponse, error)
+	MergePullRequest(context.Context, *MergePullRequestRequest) (*PullRequestResponse, error)
+	ReAssign(context.Context, *ReAssignRequest) (*ReAssignResponse, error)
+	mustEmbedUnimplementedPRServiceServer()
+}
+
+// UnimplementedPRServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPRServiceServer struct{}
+
+func (UnimplementedPRServiceServer) CreateTeam(context.Context, *CreateTeamRequest) (*CreateTeamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTeam not implemented")
+}
+func (UnimplementedPRServiceServer) GetTeam(context.Context, *GetTeamRequest) (*GetTeamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTeam not implemented")
+}
+func (UnimplementedPRServiceServer) SetIsActive(context.Context, *SetIsActiveRequest) (*SetIsActiveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetIsActive not implemented")
+}
+func (UnimplementedPRServiceServer) GetReview(context.Context, *GetReviewRequest) (*GetReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReview not implemented")
+}
+func (UnimplementedPRServiceServer) CreatePullRequest(context.Context, *CreatePullRequestRequest) (*PullRequestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePullRequest not implemented")
+}
+func (UnimplementedPRServiceServer) MergePullRequest(context.Context, *MergePullRequestRequest) (*PullRequestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergePullRequest not implemented")
+}
+func (UnimplementedPRServiceServer) ReAssign(context.Context, *ReAssignRequest) (*ReAssignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReAssign not implemented")
+}
+func (UnimplementedPRServiceServer) mustEmbedUnimplementedPRServiceServer() {}
+func (UnimplementedPRServiceServer) testEmbeddedByValue()                   {}
+
+// UnsafePRServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PRServiceServer will
+// result in compilation errors.
+type UnsafePRServiceServer interface {
+	mustEmbedUnimplementedPRServiceServer()
+}
+
+func RegisterPRServiceServer(s grpc.ServiceRegistrar, srv PRServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPRServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PRService_ServiceDesc, srv)
+}
+
+func _PRService_CreateTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).CreateTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_CreateTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).CreateTeam(ctx, req.(*CreateTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PRService_GetTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).GetTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_GetTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).GetTeam(ctx, req.(*GetTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PRService_SetIsActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetIsActiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).SetIsActive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_SetIsActive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).SetIsActive(ctx, req.(*SetIsActiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PRService_GetReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).GetReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_GetReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).GetReview(ctx, req.(*GetReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PRService_CreatePullRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePullRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).CreatePullRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_CreatePullRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).CreatePullRequest(ctx, req.(*CreatePullRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PRService_MergePullRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergePullRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).MergePullRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_MergePullRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).MergePullRequest(ctx, req.(*MergePullRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PRService_ReAssign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReAssignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PRServiceServer).ReAssign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PRService_ReAssign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PRServiceServer).ReAssign(ctx, req.(*ReAssignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PRService_ServiceDesc is the grpc.ServiceDesc for PRService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PRService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pr_service.v1.PRService",
+	HandlerType: (*PRServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTeam",
+			Handler:    _PRService_CreateTeam_Handler,
+		},
+		{
+			MethodName: "GetTeam",
+			Handler:    _PRService_GetTeam_Handler,
+		},
+		{
+			MethodName: "SetIsActive",
+			Handler:    _PRService_SetIsActive_Handler,
+		},
+		{
+			MethodName: "GetReview",
+			Handler:    _PRService_GetReview_Handler,
+		},
+		{
+			MethodName: "CreatePullRequest",
+			Handler:    _PRService_CreatePullRequest_Handler,
+		},
+		{
+			MethodName: "MergePullRequest",
+			Handler:    _PRService_MergePullRequest_Handler,
+		},
+		{
+			MethodName: "ReAssign",
+			Handler:    _PRService_ReAssign_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pr_service.proto",
+}
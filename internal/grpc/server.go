@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/grpc/pb"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"context"
+
+	"github.com/theartofdevel/logging"
+	grpclib "google.golang.org/grpc"
+)
+
+// Server exposes service.Service over gRPC; it is the gRPC counterpart of the
+// REST handlers and shares their authentication and error codes.
+type Server struct {
+	pb.UnimplementedPRServiceServer
+	svc *service.Service
+}
+
+// methodRoles lists the role each RPC requires, like the routes in handler.NewRouter.
+var methodRoles = map[string]auth.Role{
+	pb.PRService_CreateTeam_FullMethodName:        auth.RoleAdmin,
+	pb.PRService_GetTeam_FullMethodName:           auth.RoleUser,
+	pb.PRService_SetIsActive_FullMethodName:       auth.RoleAdmin,
+	pb.PRService_GetReview_FullMethodName:         auth.RoleUser,
+	pb.PRService_CreatePullRequest_FullMethodName: auth.RoleUser,
+	pb.PRService_MergePullRequest_FullMethodName:  auth.RoleAdmin,
+	pb.PRService_ReAssign_FullMethodName:          auth.RoleUser,
+}
+
+func NewServer(svc *service.Service, logger *logging.Logger, authenticator auth.Authenticator) *grpclib.Server {
+	server := grpclib.NewServer(grpclib.ChainUnaryInterceptor(
+		requestInterceptor(logger),
+		authInterceptor(authenticator),
+		errorInterceptor,
+	))
+	pb.RegisterPRServiceServer(server, &Server{svc: svc})
+	return server
+}
+
+func (s *Server) CreateTeam(ctx context.Context, req *pb.CreateTeamRequest) (*pb.CreateTeamResponse, error) {
+	members := usersFromPB(req.GetMembers())
+	settings := domain.TeamSettings{
+		ReviewerStrategy:  domain.ReviewerStrategy(req.GetReviewerStrategy()),
+		RequiredReviewers: int(req.GetRequiredReviewers()),
+		RequiredApprovals: int(req.GetRequiredApprovals()),
+		FallbackTeams:     req.GetFallbackTeams(),
+	}
+
+	if err := s.svc.CreateTeam(ctx, req.GetTeamName(), members, settings); err != nil {
+		return nil, err
+	}
+	return &pb.CreateTeamResponse{TeamName: req.GetTeamName(), Members: usersToPB(members)}, nil
+}
+
+func (s *Server) GetTeam(ctx context.Context, req *pb.GetTeamRequest) (*pb.GetTeamResponse, error) {
+	members, err := s.svc.GetTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTeamResponse{TeamName: req.GetTeamName(), Members: usersToPB(members)}, nil
+}
+
+func (s *Server) SetIsActive(ctx context.Context, req *pb.SetIsActiveRequest) (*pb.SetIsActiveResponse, error) {
+	user, teamNames, err := s.svc.SetIsActive(ctx, req.GetUserId(), req.GetIsActive())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetIsActiveResponse{User: userToPB(*user), TeamNames: teamNames}, nil
+}
+
+func (s *Server) GetReview(ctx context.Context, req *pb.GetReviewRequest) (*pb.GetReviewResponse, error) {
+	pullRequests, err := s.svc.GetReview(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetReviewResponse{
+		UserId:       req.GetUserId(),
+		PullRequests: make([]*pb.PullRequestShort, 0, len(pullRequests)),
+	}
+	for _, pr := range pullRequests {
+		resp.PullRequests = append(resp.PullRequests, &pb.PullRequestShort{
+			PullRequestId:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorId:        pr.AuthorID,
+			Status:          string(pr.Status),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) CreatePullRequest(ctx context.Context, req *pb.CreatePullRequestRequest) (*pb.PullRequestResponse, error) {
+	pullRequest, _, err := s.svc.CreatePullRequestIdempotent(ctx,
+		req.GetIdempotencyKey(), req.GetPullRequestId(), req.GetPullRequestName(), req.GetAuthorId(), req.GetTeamName(),
+		req.GetDraft(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PullRequestResponse{Pr: pullRequestToPB(pullRequest)}, nil
+}
+
+func (s *Server) MergePullRequest(ctx context.Context, req *pb.MergePullRequestRequest) (*pb.PullRequestResponse, error) {
+	pullRequest, err := s.svc.MergePullRequest(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PullRequestResponse{Pr: pullRequestToPB(pullRequest)}, nil
+}
+
+func (s *Server) ReAssign(ctx context.Context, req *pb.ReAssignRequest) (*pb.ReAssignResponse, error) {
+	pullRequest, newReviewerID, err := s.svc.ReAssign(ctx, req.GetPullRequestId(), req.GetOldUserId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ReAssignResponse{Pr: pullRequestToPB(pullRequest), ReplacedBy: newReviewerID}, nil
+}
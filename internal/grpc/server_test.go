@@ -0,0 +1,205 @@
+package grpc_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"ReilBleem13/pull_requests_service/internal/domain"
+	grpctransport "ReilBleem13/pull_requests_service/internal/grpc"
+	"ReilBleem13/pull_requests_service/internal/grpc/pb"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"context"
+	"database/sql"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theartofdevel/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(string, ...any) {}
+func (m *mockLogger) Info(string, ...any)  {}
+func (m *mockLogger) Warn(string, ...any)  {}
+func (m *mockLogger) Error(string, ...any) {}
+
+// The fakes embed the repository interfaces and implement only what the
+// tested RPCs reach; anything else panics on the nil interface.
+type fakeTeams struct {
+	service.TeamRepositoryInterface
+	teams map[string][]domain.User
+}
+
+func (f *fakeTeams) Get(_ context.Context, teamName string) ([]domain.User, error) {
+	members, ok := f.teams[teamName]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return members, nil
+}
+
+type fakePullRequests struct {
+	service.PullRequestRepositoryInterface
+	prs map[string]*domain.PullRequest
+}
+
+func (f *fakePullRequests) Merge(_ context.Context, prID string) (*domain.PullRequest, bool, error) {
+	pr, ok := f.prs[prID]
+	if !ok {
+		return nil, false, domain.ErrNotFound()
+	}
+	if len(pr.Reviewers) > 0 && pr.Reviewers[0].Decision == nil {
+		return nil, false, domain.ErrNotApproved("not enough approvals")
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	merged := *pr
+	merged.Status = domain.PRStatusMerged
+	merged.MergedAt = &now
+	return &merged, true, nil
+}
+
+func newClient(t *testing.T) pb.PRServiceClient {
+	t.Helper()
+
+	teams := &fakeTeams{teams: map[string][]domain.User{
+		"backend": {
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: false},
+		},
+	}}
+	prs := &fakePullRequests{prs: map[string]*domain.PullRequest{
+		"pr-1": {PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen},
+		"pr-2": {
+			PullRequestID: "pr-2",
+			AuthorID:      "u1",
+			Status:        domain.PRStatusOpen,
+			Reviewers:     []domain.Reviewer{{UserID: "u2"}},
+		},
+	}}
+	svc := service.NewService(nil, teams, prs, nil, &mockLogger{})
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, os.WriteFile(path, []byte("tok-admin admin root\ntok-user user u1\n"), 0o600))
+	tokens, err := auth.LoadStaticTokens(path)
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpctransport.NewServer(svc, logging.NewLogger(), tokens)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpclib.NewClient("passthrough:///bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewPRServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func assertStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "error must be a gRPC status")
+	assert.Equal(t, code, st.Code())
+
+	if reason == "" {
+		return
+	}
+	require.NotEmpty(t, st.Details())
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok, "first detail must be ErrorInfo")
+	assert.Equal(t, reason, info.GetReason())
+}
+
+func TestServer_Auth(t *testing.T) {
+	client := newClient(t)
+
+	t.Run("missing token", func(t *testing.T) {
+		_, err := client.GetTeam(context.Background(), &pb.GetTeamRequest{TeamName: "backend"})
+		assertStatus(t, err, codes.Unauthenticated, "")
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := client.GetTeam(withToken("tok-unknown"), &pb.GetTeamRequest{TeamName: "backend"})
+		assertStatus(t, err, codes.Unauthenticated, "")
+	})
+
+	t.Run("user can't merge", func(t *testing.T) {
+		_, err := client.MergePullRequest(withToken("tok-user"), &pb.MergePullRequestRequest{PullRequestId: "pr-1"})
+		assertStatus(t, err, codes.PermissionDenied, "")
+	})
+}
+
+func TestServer_GetTeam(t *testing.T) {
+	client := newClient(t)
+
+	t.Run("success", func(t *testing.T) {
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(withToken("tok-user"), "x-request-id", "req-42")
+
+		resp, err := client.GetTeam(ctx, &pb.GetTeamRequest{TeamName: "backend"}, grpclib.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, "backend", resp.GetTeamName())
+		require.Len(t, resp.GetMembers(), 2)
+		assert.Equal(t, "u1", resp.GetMembers()[0].GetUserId())
+		assert.False(t, resp.GetMembers()[1].GetIsActive())
+		assert.Equal(t, []string{"req-42"}, header.Get("x-request-id"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := client.GetTeam(withToken("tok-user"), &pb.GetTeamRequest{TeamName: "ghost"})
+		assertStatus(t, err, codes.NotFound, string(domain.CodeNotFound))
+	})
+}
+
+func TestServer_MergePullRequest(t *testing.T) {
+	client := newClient(t)
+	ctx := withToken("tok-admin")
+
+	t.Run("success", func(t *testing.T) {
+		resp, err := client.MergePullRequest(ctx, &pb.MergePullRequestRequest{PullRequestId: "pr-1"})
+		require.NoError(t, err)
+		assert.Equal(t, string(domain.PRStatusMerged), resp.GetPr().GetStatus())
+		assert.Equal(t, "backend", resp.GetPr().GetTeamName())
+		assert.Equal(t, int64(1735787045), resp.GetPr().GetMergedAt().GetSeconds())
+		assert.Nil(t, resp.GetPr().GetCreatedAt())
+	})
+
+	t.Run("not approved", func(t *testing.T) {
+		_, err := client.MergePullRequest(ctx, &pb.MergePullRequestRequest{PullRequestId: "pr-2"})
+		assertStatus(t, err, codes.FailedPrecondition, string(domain.CodeNotApproved))
+	})
+
+	t.Run("empty id", func(t *testing.T) {
+		_, err := client.MergePullRequest(ctx, &pb.MergePullRequestRequest{})
+		assertStatus(t, err, codes.InvalidArgument, string(domain.CodeInvalidRequest))
+
+		st, _ := status.FromError(err)
+		require.Len(t, st.Details(), 2)
+		badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+		require.True(t, ok, "second detail must be BadRequest")
+		require.Len(t, badRequest.GetFieldViolations(), 1)
+		assert.Equal(t, "pull_request_id", badRequest.GetFieldViolations()[0].GetField())
+		assert.Equal(t, "REQUIRED", badRequest.GetFieldViolations()[0].GetReason())
+	})
+}
@@ -7,10 +7,59 @@ import (
 
 // requests
 type createTeamDTO struct {
+	TeamName          string                  `json:"team_name"`
+	Members           []domain.User           `json:"members"`
+	ReviewerStrategy  domain.ReviewerStrategy `json:"reviewer_strategy,omitempty"`
+	RequiredReviewers int                     `json:"required_reviewers,omitempty"`
+	RequiredApprovals int                     `json:"required_approvals,omitempty"`
+	FallbackTeams     []string                `json:"fallback_teams,omitempty"`
+}
+
+type updateTeamDTO struct {
+	TeamName          string                   `json:"team_name"`
+	ReviewerStrategy  *domain.ReviewerStrategy `json:"reviewer_strategy"`
+	RequiredReviewers *int                     `json:"required_reviewers"`
+	RequiredApprovals *int                     `json:"required_approvals"`
+	FallbackTeams     []string                 `json:"fallback_teams"`
+}
+
+type deactivateTeamUsersDTO struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type addTeamMembersDTO struct {
+	TeamName string        `json:"team_name"`
+	Members  []domain.User `json:"members"`
+}
+
+type syncTeamDTO struct {
 	TeamName string        `json:"team_name"`
 	Members  []domain.User `json:"members"`
 }
 
+type removeTeamMembersDTO struct {
+	TeamName        string   `json:"team_name"`
+	UserIDs         []string `json:"user_ids"`
+	ReassignReviews bool     `json:"reassign_reviews"`
+}
+
+type moveTeamMemberDTO struct {
+	UserID   string `json:"user_id"`
+	FromTeam string `json:"from_team"`
+	ToTeam   string `json:"to_team"`
+}
+
+type registerWebhookDTO struct {
+	TeamName string `json:"team_name"`
+	URL      string `json:"url"`
+	Secret   string `json:"secret,omitempty"`
+}
+
+type deleteWebhookDTO struct {
+	WebhookID int64 `json:"webhook_id"`
+}
+
 type setIsActiveDTO struct {
 	UserID   string `json:"user_id"`
 	IsActive bool   `json:"is_active"`
@@ -20,12 +69,24 @@ type createPullRequestDTO struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	TeamName        string `json:"team_name,omitempty"`
+	Draft           bool   `json:"draft,omitempty"`
+}
+
+type changeStatusDTO struct {
+	PullRequestID string `json:"pull_request_id"`
 }
 
 type doMergedRequestDTO struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+type reviewDTO struct {
+	PullRequestID string                `json:"pull_request_id"`
+	ReviewerID    string                `json:"reviewer_id"`
+	Decision      domain.ReviewDecision `json:"decision"`
+}
+
 type reassignDTO struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
@@ -40,23 +101,28 @@ type getTeamUsersDTO struct {
 type getSetUserDTO struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	// Deprecated: TeamName is the first of TeamNames, kept for clients that
+	// predate users in several teams.
+	TeamName  string   `json:"team_name"`
+	TeamNames []string `json:"team_names"`
+	IsActive  bool     `json:"is_active"`
 }
 
 type getMergedDTO struct {
-	PullRequestID     string          `json:"pull_request_id"`
-	PullRequestName   string          `json:"pull_request_name"`
-	AuthorID          string          `json:"author_id"`
-	Status            domain.PRStatus `json:"status"`
-	AssignedReviewers []string        `json:"assigned_reviewers"`
-	MergedAt          *time.Time      `json:"merged_at"`
+	PullRequestID     string            `json:"pull_request_id"`
+	PullRequestName   string            `json:"pull_request_name"`
+	AuthorID          string            `json:"author_id"`
+	Status            domain.PRStatus   `json:"status"`
+	AssignedReviewers []string          `json:"assigned_reviewers"`
+	Reviewers         []domain.Reviewer `json:"reviewers"`
+	MergedAt          *time.Time        `json:"merged_at"`
 }
 
 type getAssignedDTO struct {
-	PullRequestID     string          `json:"pull_request_id"`
-	PullRequestName   string          `json:"pull_request_name"`
-	AuthorID          string          `json:"author_id"`
-	Status            domain.PRStatus `json:"status"`
-	AssignedReviewers []string        `json:"assigned_reviewers"`
+	PullRequestID     string            `json:"pull_request_id"`
+	PullRequestName   string            `json:"pull_request_name"`
+	AuthorID          string            `json:"author_id"`
+	Status            domain.PRStatus   `json:"status"`
+	AssignedReviewers []string          `json:"assigned_reviewers"`
+	Reviewers         []domain.Reviewer `json:"reviewers"`
 }
@@ -11,8 +11,9 @@ import (
 
 type apiErrorResponse struct {
 	Error struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code    string              `json:"code"`
+		Message string              `json:"message"`
+		Details []domain.FieldError `json:"details,omitempty"`
 	} `json:"error"`
 }
 
@@ -22,41 +23,57 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
-func writeAPIResponse(w http.ResponseWriter, status int, code, message string) {
+func writeAPIResponse(w http.ResponseWriter, status int, code, message string, details ...domain.FieldError) {
 	resp := apiErrorResponse{}
 	resp.Error.Code = code
 	resp.Error.Message = message
+	resp.Error.Details = details
 	writeJSON(w, status, resp)
 }
 
-func (h *Handler) WriteError(w http.ResponseWriter, err error) {
+// WriteError writes err as an API error response and logs it with the
+// request-scoped logger.
+func (h *Handler) WriteError(w http.ResponseWriter, r *http.Request, err error) {
 	if err == nil {
 		return
 	}
 
 	var appErr *domain.AppError
 	if errors.As(err, &appErr) {
-		h.logger.Error("application error",
+		logging.L(r.Context()).Error("application error",
 			logging.StringAttr("code", string(appErr.Code)),
 			logging.StringAttr("message", appErr.Message),
 			logging.ErrAttr(appErr.Cause),
 		)
-		writeAPIResponse(w, statusFromCode(appErr.Code), string(appErr.Code), appErr.Message)
+		writeAPIResponse(w, statusFromCode(appErr.Code), string(appErr.Code), appErr.Message, appErr.Details...)
 		return
 	}
-	h.logger.Error("unexpected error", logging.ErrAttr(err))
-	writeAPIResponse(w, http.StatusInternalServerError, "SERVER_ERROR", "internal server error")
+	logging.L(r.Context()).Error("unexpected error", logging.ErrAttr(err))
+	writeAPIResponse(w, http.StatusInternalServerError, string(domain.CodeInternalError), "internal server error")
+}
+
+// codeStatuses maps every error code to its HTTP status; codes missing here
+// are answered with 500.
+var codeStatuses = map[domain.ErrorCode]int{
+	domain.CodeInvalidRequest:       http.StatusBadRequest,
+	domain.CodeUnauthorized:         http.StatusUnauthorized,
+	domain.CodeForbidden:            http.StatusForbidden,
+	domain.CodeNotFound:             http.StatusNotFound,
+	domain.CodeTeamExists:           http.StatusConflict,
+	domain.CodePRExists:             http.StatusConflict,
+	domain.CodeWebhookExists:        http.StatusConflict,
+	domain.CodePRMerged:             http.StatusConflict,
+	domain.CodeNotAssigned:          http.StatusConflict,
+	domain.CodeNoCandidate:          http.StatusConflict,
+	domain.CodeNotApproved:          http.StatusConflict,
+	domain.CodeInvalidTransition:    http.StatusConflict,
+	domain.CodeIdempotencyKeyReused: http.StatusConflict,
+	domain.CodeInternalError:        http.StatusInternalServerError,
 }
 
 func statusFromCode(code domain.ErrorCode) int {
-	switch code {
-	case domain.CodeTeamExists:
-		return http.StatusBadRequest
-	case domain.CodePRExists, domain.CodePRMerged, domain.CodeNotAssigned, domain.CodeNoCandidate:
-		return http.StatusConflict
-	case domain.CodeNotFound:
-		return http.StatusNotFound
-	default:
-		return http.StatusNotFound
+	if status, ok := codeStatuses[code]; ok {
+		return status
 	}
+	return http.StatusInternalServerError
 }
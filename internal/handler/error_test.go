@@ -0,0 +1,203 @@
+package handler_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The fakes embed the repository interfaces and fail every implemented call
+// with err; anything else panics on the nil interface.
+type fakeTeams struct {
+	service.TeamRepositoryInterface
+	err error
+}
+
+func (f *fakeTeams) Get(context.Context, string) ([]domain.User, error) {
+	return nil, f.err
+}
+
+type fakeUsers struct {
+	service.UserRepositoryInterface
+	err error
+}
+
+func (f *fakeUsers) GetUser(context.Context, string) (*domain.User, error) {
+	return nil, f.err
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var body errorBody
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body
+}
+
+func TestRouter_ErrorStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"invalid request", domain.ErrInvalidRequest("bad"), http.StatusBadRequest, "INVALID_REQUEST", "bad"},
+		{"unauthorized", domain.ErrUnauthorized(), http.StatusUnauthorized, "UNAUTHORIZED", "missing or invalid bearer token"},
+		{"forbidden", domain.ErrForbidden(), http.StatusForbidden, "FORBIDDEN", "the token's role is not allowed to use this endpoint"},
+		{"not found", domain.ErrNotFound(), http.StatusNotFound, "NOT_FOUND", "resource not found"},
+		{"no rows", sql.ErrNoRows, http.StatusNotFound, "NOT_FOUND", "resource not found"},
+		{"team exists", domain.ErrTeamExists(), http.StatusConflict, "TEAM_EXISTS", "team_name already exists"},
+		{"pr exists", domain.ErrPRExists(), http.StatusConflict, "PR_EXISTS", "PR id already exists"},
+		{"webhook exists", domain.ErrWebhookExists(), http.StatusConflict, "WEBHOOK_EXISTS", "webhook with this url already exists for the team"},
+		{"pr merged", domain.ErrPRMerged(), http.StatusConflict, "PR_MERGED", "cannot reassign on merged PR"},
+		{"not assigned", domain.ErrNotAssigned(), http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR"},
+		{"no candidate", domain.ErrNoCandidate(), http.StatusConflict, "NO_CANDIDATE", "no active replacement candidate in team"},
+		{"not approved", domain.ErrNotApproved("not enough approvals"), http.StatusConflict, "NOT_APPROVED", "not enough approvals"},
+		{
+			"invalid transition", domain.ErrInvalidTransition(domain.PRStatusMerged, domain.PRStatusOpen),
+			http.StatusConflict, "INVALID_TRANSITION", "cannot move PR from MERGED to OPEN",
+		},
+		{
+			"idempotency key reused", domain.ErrIdempotencyKeyReused(),
+			http.StatusConflict, "IDEMPOTENCY_KEY_REUSED", "idempotency key was already used with a different request",
+		},
+		{
+			"wrapped app error", fmt.Errorf("team is not exist: %w", domain.ErrNotFound()),
+			http.StatusNotFound, "NOT_FOUND", "resource not found",
+		},
+		{
+			"internal app error", &domain.AppError{Code: domain.CodeInternalError, Message: "internal server error"},
+			http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "internal server error",
+		},
+		{
+			"unknown code", &domain.AppError{Code: "SOMETHING_NEW", Message: "unexpected"},
+			http.StatusInternalServerError, "SOMETHING_NEW", "unexpected",
+		},
+		{
+			"database error", errors.New("pq: connection refused"),
+			http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewService(nil, &fakeTeams{err: tt.err}, nil, nil, &mockLogger{})
+			rec := doRequest(newServiceRouter(t, svc), http.MethodGet, "/team/get?team_name=backend", "")
+
+			require.Equal(t, tt.status, rec.Code)
+			body := decodeError(t, rec)
+			assert.Equal(t, tt.code, body.Error.Code)
+			assert.Equal(t, tt.message, body.Error.Message)
+			assert.Empty(t, body.Error.Details)
+		})
+	}
+}
+
+func TestRouter_AuthErrors(t *testing.T) {
+	router := newTestRouter(t)
+
+	t.Run("missing token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "UNAUTHORIZED", decodeError(t, rec).Error.Code)
+	})
+
+	t.Run("role not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{"pull_request_id": "pr-1"}`))
+		req.Header.Set("Authorization", "Bearer tok-user")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, "FORBIDDEN", decodeError(t, rec).Error.Code)
+	})
+
+	t.Run("review on behalf of another reviewer", func(t *testing.T) {
+		svc := service.NewService(nil, nil, &fakePullRequests{}, nil, &mockLogger{})
+		review := func(token, reviewerID string) *httptest.ResponseRecorder {
+			body := `{"pull_request_id": "pr-1", "reviewer_id": "` + reviewerID + `", "decision": "APPROVED"}`
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/review", strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			newServiceRouter(t, svc).ServeHTTP(rec, req)
+			return rec
+		}
+
+		// tok-user is issued to u1
+		rec := review("tok-user", "u2")
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		body := decodeError(t, rec)
+		assert.Equal(t, "FORBIDDEN", body.Error.Code)
+		assert.Equal(t, "only the reviewer or an admin can submit the review", body.Error.Message)
+
+		assert.Equal(t, http.StatusOK, review("tok-user", "u1").Code)
+		assert.Equal(t, http.StatusOK, review("tok-admin", "u2").Code)
+	})
+}
+
+func TestRouter_CreatePullRequestErrors(t *testing.T) {
+	const target = "/pullRequest/create"
+
+	t.Run("service validation", func(t *testing.T) {
+		rec := doRequest(newTestRouter(t), http.MethodPost, target,
+			`{"pull_request_id": "pr 1", "pull_request_name": "Add search", "author_id": "u1"}`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		body := decodeError(t, rec)
+		assert.Equal(t, "INVALID_REQUEST", body.Error.Code)
+		require.Len(t, body.Error.Details, 1)
+		assert.Equal(t, "pull_request_id", body.Error.Details[0].Field)
+		assert.Equal(t, "charset", body.Error.Details[0].Rule)
+	})
+
+	t.Run("unknown author", func(t *testing.T) {
+		users := &fakeUsers{err: fmt.Errorf("user is not exist: %w", domain.ErrNotFound())}
+		svc := service.NewService(users, nil, nil, nil, &mockLogger{})
+		rec := doRequest(newServiceRouter(t, svc), http.MethodPost, target,
+			`{"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "ghost"}`)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "NOT_FOUND", decodeError(t, rec).Error.Code)
+	})
+
+	t.Run("author id created before the id rules", func(t *testing.T) {
+		users := &fakeUsers{err: fmt.Errorf("user is not exist: %w", domain.ErrNotFound())}
+		svc := service.NewService(users, nil, nil, nil, &mockLogger{})
+		rec := doRequest(newServiceRouter(t, svc), http.MethodPost, target,
+			`{"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "dev@corp"}`)
+
+		// the author is looked up, not rejected as an invalid new id
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "NOT_FOUND", decodeError(t, rec).Error.Code)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		users := &fakeUsers{err: errors.New("pq: connection refused")}
+		svc := service.NewService(users, nil, nil, nil, &mockLogger{})
+		rec := doRequest(newServiceRouter(t, svc), http.MethodPost, target,
+			`{"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "u1"}`)
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		body := decodeError(t, rec)
+		assert.Equal(t, "INTERNAL_SERVER_ERROR", body.Error.Code)
+		assert.Equal(t, "internal server error", body.Error.Message)
+	})
+}
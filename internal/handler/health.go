@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/theartofdevel/logging"
+)
+
+// Database is what the readiness probe checks before the instance gets traffic.
+type Database interface {
+	Ping(ctx context.Context) error
+	CheckSchema(ctx context.Context) error
+}
+
+// Readiness tells whether the instance should receive traffic: the database
+// must answer within timeout with an up-to-date schema, and the instance must
+// not be shutting down.
+type Readiness struct {
+	db       Database
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewReadiness(db Database, timeout time.Duration) *Readiness {
+	return &Readiness{
+		db:      db,
+		timeout: timeout,
+	}
+}
+
+// StartDraining makes the instance report not ready from now on, so that load
+// balancers stop sending requests before the server shuts down.
+func (r *Readiness) StartDraining() {
+	r.draining.Store(true)
+}
+
+// The reasons the probe reports; the underlying errors only go to the log, as
+// the probe is public and they may carry driver and connection details.
+var (
+	errDraining            = errors.New("shutting down")
+	errDatabaseUnavailable = errors.New("database is unavailable")
+	errSchemaNotReady      = errors.New("database schema is not up to date")
+)
+
+func (r *Readiness) Check(ctx context.Context) error {
+	if r.draining.Load() {
+		return errDraining
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := r.db.Ping(ctx); err != nil {
+		return fmt.Errorf("%w: %w", errDatabaseUnavailable, err)
+	}
+	if err := r.db.CheckSchema(ctx); err != nil {
+		return fmt.Errorf("%w: %w", errSchemaNotReady, err)
+	}
+	return nil
+}
+
+// readinessReason is the fixed reason of a failed Check.
+func readinessReason(err error) string {
+	for _, reason := range []error{errDraining, errDatabaseUnavailable, errSchemaNotReady} {
+		if errors.Is(err, reason) {
+			return reason.Error()
+		}
+	}
+	return "not ready"
+}
+
+// GET /health/live
+func (h *Handler) handleLive(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+// GET /health/ready
+func (h *Handler) handleReady(w http.ResponseWriter, r *http.Request) {
+	if err := h.readiness.Check(r.Context()); err != nil {
+		logging.L(r.Context()).Warn("instance is not ready", logging.ErrAttr(err))
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{
+			"status": "not ready",
+			"reason": readinessReason(err),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ready"})
+}
@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/handler"
+	"ReilBleem13/pull_requests_service/internal/metrics"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theartofdevel/logging"
+)
+
+type fakeDatabase struct {
+	pingErr   error
+	schemaErr error
+	// hang makes Ping wait for the deadline of the probe
+	hang bool
+}
+
+func (f *fakeDatabase) Ping(ctx context.Context) error {
+	if f.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return f.pingErr
+}
+
+func (f *fakeDatabase) CheckSchema(context.Context) error {
+	return f.schemaErr
+}
+
+func newHealthRouter(t *testing.T, readiness *handler.Readiness) http.Handler {
+	t.Helper()
+
+	svc := service.NewService(nil, nil, nil, nil, &mockLogger{})
+	router, err := handler.NewRouter(svc, logging.NewLogger(), metrics.New(nil), readiness, newTestTokens(t))
+	require.NoError(t, err)
+	return router
+}
+
+func TestRouter_Ready(t *testing.T) {
+	tests := []struct {
+		name   string
+		db     *fakeDatabase
+		drain  bool
+		status int
+		reason string
+	}{
+		{name: "ready", db: &fakeDatabase{}, status: http.StatusOK},
+		{
+			name:   "ping failure",
+			db:     &fakeDatabase{pingErr: errors.New(`dial tcp 10.0.0.5:5432: connect: connection refused`)},
+			status: http.StatusServiceUnavailable,
+			reason: "database is unavailable",
+		},
+		{
+			name:   "ping timeout",
+			db:     &fakeDatabase{hang: true},
+			status: http.StatusServiceUnavailable,
+			reason: "database is unavailable",
+		},
+		{
+			name:   "dirty schema",
+			db:     &fakeDatabase{schemaErr: errors.New("migration 12 is dirty")},
+			status: http.StatusServiceUnavailable,
+			reason: "database schema is not up to date",
+		},
+		{
+			name:   "schema too old",
+			db:     &fakeDatabase{schemaErr: errors.New("schema version is 11, expected at least 12")},
+			status: http.StatusServiceUnavailable,
+			reason: "database schema is not up to date",
+		},
+		{
+			name:   "draining",
+			db:     &fakeDatabase{},
+			drain:  true,
+			status: http.StatusServiceUnavailable,
+			reason: "shutting down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readiness := handler.NewReadiness(tt.db, 20*time.Millisecond)
+			if tt.drain {
+				readiness.StartDraining()
+			}
+
+			rec := httptest.NewRecorder()
+			newHealthRouter(t, readiness).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+			require.Equal(t, tt.status, rec.Code)
+
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			if tt.status == http.StatusOK {
+				assert.Equal(t, map[string]string{"status": "ready"}, body)
+				return
+			}
+			// details of the error stay in the log
+			assert.Equal(t, map[string]string{"status": "not ready", "reason": tt.reason}, body)
+		})
+	}
+}
+
+func TestRouter_Live(t *testing.T) {
+	readiness := handler.NewReadiness(&fakeDatabase{pingErr: errors.New("down")}, time.Second)
+	readiness.StartDraining()
+
+	rec := httptest.NewRecorder()
+	newHealthRouter(t, readiness).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,64 @@
+package handler_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/metrics"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePullRequests struct {
+	service.PullRequestRepositoryInterface
+	merged map[string]bool
+}
+
+func (f *fakePullRequests) Merge(_ context.Context, prID string) (*domain.PullRequest, bool, error) {
+	wasMerged := f.merged[prID]
+	f.merged[prID] = true
+	pr := &domain.PullRequest{PullRequestID: prID, PullRequestName: "Add search", AuthorID: "u1", Status: domain.PRStatusMerged}
+	return pr, !wasMerged, nil
+}
+
+func (f *fakePullRequests) SubmitReview(_ context.Context, prID, reviewerID string, decision domain.ReviewDecision) (*domain.PullRequest, error) {
+	pr := &domain.PullRequest{PullRequestID: prID, PullRequestName: "Add search", AuthorID: "u0", Status: domain.PRStatusOpen}
+	pr.Reviewers = []domain.Reviewer{{UserID: reviewerID, Decision: &decision}}
+	return pr, nil
+}
+
+func TestRouter_Metrics(t *testing.T) {
+	m := metrics.New(nil)
+	svc := service.NewService(nil, &fakeTeams{err: domain.ErrNotFound()}, &fakePullRequests{merged: map[string]bool{}}, nil,
+		&mockLogger{}, service.WithMetrics(m))
+	router := newMetricsRouter(t, svc, m)
+
+	// the second merge of the same PR is a no-op and isn't counted
+	for range 2 {
+		rec := doRequest(router, http.MethodPost, "/pullRequest/merge", `{"pull_request_id": "pr-1"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+	for _, team := range []string{"ghost", "phantom"} {
+		rec := doRequest(router, http.MethodGet, "/team/get?team_name="+team, "")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	}
+	rec := doRequest(router, http.MethodPost, "/pullRequest/merge", `{}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(router, http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `pr_service_http_requests_total{method="POST",route="/pullRequest/merge",status="200"} 2`)
+	assert.Contains(t, body, `pr_service_http_requests_total{method="POST",route="/pullRequest/merge",status="400"} 1`)
+	assert.Contains(t, body, `pr_service_http_requests_total{method="GET",route="/team/get",status="404"} 2`)
+	assert.Contains(t, body, `pr_service_http_request_duration_seconds_count{method="GET",route="/team/get",status="404"} 2`)
+	assert.NotContains(t, body, "ghost")
+	assert.NotContains(t, body, "team_name=")
+
+	assert.Contains(t, body, "pr_service_pull_requests_merged_total 1\n")
+	assert.Contains(t, body, "pr_service_pull_requests_created_total 0\n")
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/metrics"
+	"ReilBleem13/pull_requests_service/internal/requestid"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/theartofdevel/logging"
+)
+
+// withAuth authenticates the bearer token of the request and lets it through
+// only if the caller's role allows required. The token subject becomes the
+// actor recorded in the history of changes made by the request.
+func (h *Handler) withAuth(required auth.Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			h.WriteError(w, r, domain.ErrUnauthorized())
+			return
+		}
+
+		principal, err := h.authenticator.Authenticate(token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				err = domain.ErrUnauthorized()
+			}
+			h.WriteError(w, r, err)
+			return
+		}
+
+		ctx := domain.ContextWithActor(r.Context(), principal.Subject)
+		if principal.Role == auth.RoleAdmin {
+			ctx = domain.ContextWithAdmin(ctx)
+		}
+		ctx = logging.ContextWithLogger(ctx, logging.L(ctx).With(
+			logging.StringAttr("actor", principal.Subject),
+			logging.StringAttr("role", string(principal.Role)),
+		))
+		r = r.WithContext(ctx)
+
+		if !principal.Role.Allows(required) {
+			h.WriteError(w, r, domain.ErrForbidden())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withRequestLogging takes the request id from the X-Request-ID header or
+// generates a new one, echoes it in the response and puts a logger carrying
+// it into the request context. Once the request is served it logs the method,
+// path, status, response size and latency.
+func withRequestLogging(logger *logging.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		ctx, requestLogger, requestID := requestid.Start(r.Context(), logger, r.Header.Get(requestid.Header))
+		w.Header().Set(requestid.Header, requestID)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		requestLogger.Info("http request",
+			logging.StringAttr("method", r.Method),
+			logging.StringAttr("path", r.URL.Path),
+			logging.IntAttr("status", recorder.status),
+			logging.IntAttr("bytes", recorder.bytes),
+			logging.IntAttr("latency_ms", int(time.Since(start).Milliseconds())),
+		)
+	})
+}
+
+// withMetrics records the count and latency of requests served by next under
+// the registered route pattern.
+func withMetrics(m *metrics.Metrics, route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		m.ObserveHTTPRequest(route, r.Method, recorder.status, time.Since(start))
+	})
+}
+
+// statusRecorder remembers the status code and the number of bytes written
+// by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
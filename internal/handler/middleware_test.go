@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/requestid"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theartofdevel/logging"
+)
+
+// serveLogged passes req through withRequestLogging to next and returns the
+// response and the logged entries; the last one is the request log line.
+func serveLogged(t *testing.T, req *http.Request, next http.HandlerFunc) (*httptest.ResponseRecorder, []map[string]any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rec := httptest.NewRecorder()
+	withRequestLogging(logger, next).ServeHTTP(rec, req)
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	require.Equal(t, "http request", entries[len(entries)-1]["msg"])
+	return rec, entries
+}
+
+func TestWithRequestLogging_RequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "propagate incoming id", incoming: "req-42", keep: true},
+		{name: "generate missing id"},
+		{name: "replace too long id", incoming: strings.Repeat("a", requestid.MaxLength+1)},
+		{name: "keep id of max length", incoming: strings.Repeat("b", requestid.MaxLength), keep: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+
+			var ctxID string
+			rec, entries := serveLogged(t, req, func(w http.ResponseWriter, r *http.Request) {
+				ctxID = domain.RequestIDFromContext(r.Context())
+				logging.L(r.Context()).Info("inside")
+			})
+			require.Len(t, entries, 2)
+
+			requestID := rec.Header().Get(requestid.Header)
+			if tt.keep {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+				assert.NotEqual(t, tt.incoming, requestID)
+			}
+			assert.Equal(t, requestID, ctxID)
+			// the logger of the context carries the id too
+			assert.Equal(t, requestID, entries[0]["request_id"])
+			assert.Equal(t, requestID, entries[1]["request_id"])
+		})
+	}
+
+	t.Run("generate a new id per request", func(t *testing.T) {
+		first, _ := serveLogged(t, httptest.NewRequest(http.MethodGet, "/", nil), func(http.ResponseWriter, *http.Request) {})
+		second, _ := serveLogged(t, httptest.NewRequest(http.MethodGet, "/", nil), func(http.ResponseWriter, *http.Request) {})
+		assert.NotEqual(t, first.Header().Get(requestid.Header), second.Header().Get(requestid.Header))
+	})
+}
+
+func TestWithRequestLogging_StatusAndBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		next   http.HandlerFunc
+		status int
+		bytes  int
+	}{
+		{
+			name:   "implicit 200 on write",
+			next:   func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("hello")) },
+			status: http.StatusOK,
+			bytes:  5,
+		},
+		{
+			name:   "implicit 200 without body",
+			next:   func(http.ResponseWriter, *http.Request) {},
+			status: http.StatusOK,
+		},
+		{
+			name: "explicit status and several writes",
+			next: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("ab"))
+				_, _ = w.Write([]byte("cde"))
+			},
+			status: http.StatusCreated,
+			bytes:  5,
+		},
+		{
+			name: "status after write is ignored",
+			next: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("x"))
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			status: http.StatusOK,
+			bytes:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/pullRequest/create?x=1", nil)
+			rec, entries := serveLogged(t, req, tt.next)
+			entry := entries[len(entries)-1]
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, float64(tt.status), entry["status"])
+			assert.Equal(t, float64(tt.bytes), entry["bytes"])
+			assert.Equal(t, http.MethodPost, entry["method"])
+			assert.Equal(t, "/pullRequest/create", entry["path"])
+			assert.Contains(t, entry, "latency_ms")
+		})
+	}
+}
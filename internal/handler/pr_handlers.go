@@ -2,53 +2,82 @@ package handler
 
 import (
 	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/theartofdevel/logging"
 )
 
 // POST /pullRequest/create
 func (h *Handler) handlePullRequestCreate(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req createPullRequestDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("invalid request body", logging.ErrAttr(err))
-		h.WriteError(w, domain.ErrInvalidRequest("invalid json payload"))
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
 		return
 	}
 
-	createdPR, err := h.svc.CreatePullRequest(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	createdPR, replayed, err := h.svc.CreatePullRequestIdempotent(r.Context(),
+		r.Header.Get("Idempotency-Key"), req.PullRequestID, req.PullRequestName, req.AuthorID, req.TeamName,
+		req.Draft,
+	)
 	if err != nil {
-		h.WriteError(w, err)
+		h.WriteError(w, r, err)
 		return
 	}
 
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
 	// добавить структуру для ответа
 	writeJSON(w, 201, map[string]any{"pr": createdPR})
 }
 
-// POST /pullRequest/merge
-func (h *Handler) handlePullRequestMerge(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+// GET /pullRequest/get
+func (h *Handler) handlePullRequestGet(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	pullRequest, err := h.svc.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pullRequest})
+}
+
+// GET /pullRequest/history
+func (h *Handler) handlePullRequestHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	events, err := h.svc.GetPullRequestHistory(r.Context(), prID)
+	if err != nil {
+		h.WriteError(w, r, err)
 		return
 	}
 
+	writeJSON(w, http.StatusOK, map[string]any{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
+// POST /pullRequest/merge
+func (h *Handler) handlePullRequestMerge(w http.ResponseWriter, r *http.Request) {
 	var req doMergedRequestDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("invalid request body", logging.ErrAttr(err))
-		h.WriteError(w, domain.ErrInvalidRequest("invalid json payload"))
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
 		return
 	}
 
 	pullRequest, err := h.svc.MergePullRequest(r.Context(), req.PullRequestID)
 	if err != nil {
-		h.WriteError(w, err)
+		h.WriteError(w, r, err)
 		return
 	}
 
@@ -58,6 +87,7 @@ func (h *Handler) handlePullRequestMerge(w http.ResponseWriter, r *http.Request)
 		AuthorID:          pullRequest.AuthorID,
 		Status:            pullRequest.Status,
 		AssignedReviewers: pullRequest.AssignedReviewers,
+		Reviewers:         pullRequest.Reviewers,
 		MergedAt:          pullRequest.MergedAt,
 	}
 
@@ -66,21 +96,16 @@ func (h *Handler) handlePullRequestMerge(w http.ResponseWriter, r *http.Request)
 
 // POST /pullRequest/create
 func (h *Handler) handlePullRequestReassign(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req reassignDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("invalid request body", logging.ErrAttr(err))
-		h.WriteError(w, domain.ErrInvalidRequest("invalid json payload"))
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
 		return
 	}
 
 	pullRequest, newReviewerID, err := h.svc.ReAssign(r.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
-		h.WriteError(w, err)
+		h.WriteError(w, r, err)
 		return
 	}
 
@@ -90,6 +115,7 @@ func (h *Handler) handlePullRequestReassign(w http.ResponseWriter, r *http.Reque
 		AuthorID:          pullRequest.AuthorID,
 		Status:            pullRequest.Status,
 		AssignedReviewers: pullRequest.AssignedReviewers,
+		Reviewers:         pullRequest.Reviewers,
 	}
 
 	writeJSON(w, 200,
@@ -99,3 +125,117 @@ func (h *Handler) handlePullRequestReassign(w http.ResponseWriter, r *http.Reque
 		},
 	)
 }
+
+// POST /pullRequest/review
+func (h *Handler) handlePullRequestReview(w http.ResponseWriter, r *http.Request) {
+	var req reviewDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	pullRequest, err := h.svc.SubmitReview(r.Context(), req.PullRequestID, req.ReviewerID, req.Decision)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pullRequest})
+}
+
+// POST /pullRequest/ready
+func (h *Handler) handlePullRequestReady(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, h.svc.ReadyPullRequest)
+}
+
+// POST /pullRequest/close
+func (h *Handler) handlePullRequestClose(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, h.svc.ClosePullRequest)
+}
+
+// POST /pullRequest/reopen
+func (h *Handler) handlePullRequestReopen(w http.ResponseWriter, r *http.Request) {
+	h.handleStatusChange(w, r, h.svc.ReopenPullRequest)
+}
+
+func (h *Handler) handleStatusChange(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, prID string) (*domain.PullRequest, error),
+) {
+	var req changeStatusDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	pullRequest, err := change(r.Context(), req.PullRequestID)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pullRequest})
+}
+
+// GET /pullRequest/list
+func (h *Handler) handlePullRequestList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := domain.PullRequestFilter{
+		Status:     domain.PRStatus(query.Get("status")),
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		TeamName:   query.Get("team_name"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			h.WriteError(w, r, domain.ErrValidation(domain.FieldError{
+				Field:   "limit",
+				Rule:    domain.RuleType,
+				Message: "limit must be an integer",
+			}))
+			return
+		}
+		filter.Limit = n
+	}
+
+	timeParams := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	}
+	for _, param := range timeParams {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.WriteError(w, r, domain.ErrValidation(domain.FieldError{
+				Field:   param.name,
+				Rule:    domain.RuleFormat,
+				Message: param.name + " must be an RFC 3339 timestamp",
+			}))
+			return
+		}
+		*param.dst = &t
+	}
+
+	page, err := h.svc.ListPullRequests(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
@@ -1,49 +1,118 @@
 package handler
 
 import (
+	"ReilBleem13/pull_requests_service/api"
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"ReilBleem13/pull_requests_service/internal/metrics"
 	"ReilBleem13/pull_requests_service/internal/service"
+	"errors"
 	"net/http"
+
+	"github.com/theartofdevel/logging"
 )
 
 type Handler struct {
-	svc    *service.Service
-	logger service.LoggerInterfaces
+	svc           *service.Service
+	logger        *logging.Logger
+	readiness     *Readiness
+	authenticator auth.Authenticator
 }
 
-func NewHandler(svc *service.Service, logger service.LoggerInterfaces) *Handler {
+func NewHandler(
+	svc *service.Service,
+	logger *logging.Logger,
+	readiness *Readiness,
+	authenticator auth.Authenticator,
+) *Handler {
 	return &Handler{
-		svc:    svc,
-		logger: logger,
+		svc:           svc,
+		logger:        logger,
+		readiness:     readiness,
+		authenticator: authenticator,
 	}
 }
 
-func NewRouter(svc *service.Service, logger service.LoggerInterfaces) *http.ServeMux {
-	h := NewHandler(svc, logger)
+// NewRouter registers the API routes with their methods, so that other
+// methods get 405 with an Allow header from the mux. Requests to the
+// authenticated routes are validated against the OpenAPI spec.
+func NewRouter(
+	svc *service.Service,
+	logger *logging.Logger,
+	m *metrics.Metrics,
+	readiness *Readiness,
+	authenticator auth.Authenticator,
+) (http.Handler, error) {
+	h := NewHandler(svc, logger, readiness, authenticator)
+
+	validator, err := newRequestValidator(api.Spec)
+	if err != nil {
+		return nil, err
+	}
 
 	mux := http.NewServeMux()
+	var errs []error
 
-	mux.HandleFunc("/team/add", h.handleCreateTeam)
-	mux.HandleFunc("/team/get", h.handleGetTeam)
+	// route labels of the metrics are the registered paths, so that
+	// arbitrary URLs can't blow up the number of series
+	public := func(method, path string, handler http.HandlerFunc) {
+		mux.Handle(method+" "+path, withMetrics(m, path, handler))
+	}
+	// user endpoints accept either role, admin ones only admin tokens
+	protected := func(role auth.Role, method, path string, handler http.HandlerFunc) {
+		validated, err := validator.wrap(method, path, handler)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		mux.Handle(method+" "+path, withMetrics(m, path, h.withAuth(role, validated)))
+	}
+	user := func(method, path string, handler http.HandlerFunc) {
+		protected(auth.RoleUser, method, path, handler)
+	}
+	admin := func(method, path string, handler http.HandlerFunc) {
+		protected(auth.RoleAdmin, method, path, handler)
+	}
 
-	mux.HandleFunc("/users/setIsActive", h.handleSetIsActive)
-	mux.HandleFunc("/users/getReview", h.handleGetReview)
+	admin(http.MethodPost, "/team/add", h.handleCreateTeam)
+	user(http.MethodGet, "/team/get", h.handleGetTeam)
+	admin(http.MethodPost, "/team/update", h.handleUpdateTeam)
+	admin(http.MethodPost, "/team/deactivateUsers", h.handleDeactivateTeamUsers)
+	admin(http.MethodPost, "/team/addMembers", h.handleAddTeamMembers)
+	admin(http.MethodPost, "/team/removeMembers", h.handleRemoveTeamMembers)
+	admin(http.MethodPost, "/team/moveMember", h.handleMoveTeamMember)
+	admin(http.MethodPut, "/team/sync", h.handleSyncTeam)
 
-	mux.HandleFunc("/pullRequest/create", h.handlePullRequestCreate)
-	mux.HandleFunc("/pullRequest/merge", h.handlePullRequestMerge)
-	mux.HandleFunc("/pullRequest/reassign", h.handlePullRequestReassign)
+	admin(http.MethodPost, "/users/setIsActive", h.handleSetIsActive)
+	user(http.MethodGet, "/users/getReview", h.handleGetReview)
 
-	// mux.HandleFunc("/stats", nil)
-	mux.HandleFunc("/health", h.handleHealth)
+	user(http.MethodPost, "/pullRequest/create", h.handlePullRequestCreate)
+	user(http.MethodGet, "/pullRequest/get", h.handlePullRequestGet)
+	user(http.MethodGet, "/pullRequest/history", h.handlePullRequestHistory)
+	admin(http.MethodPost, "/pullRequest/merge", h.handlePullRequestMerge)
+	user(http.MethodPost, "/pullRequest/reassign", h.handlePullRequestReassign)
+	user(http.MethodPost, "/pullRequest/review", h.handlePullRequestReview)
+	user(http.MethodPost, "/pullRequest/ready", h.handlePullRequestReady)
+	user(http.MethodPost, "/pullRequest/close", h.handlePullRequestClose)
+	user(http.MethodPost, "/pullRequest/reopen", h.handlePullRequestReopen)
+	user(http.MethodGet, "/pullRequest/list", h.handlePullRequestList)
 
-	return mux
-}
+	admin(http.MethodPost, "/webhook/register", h.handleRegisterWebhook)
+	user(http.MethodGet, "/webhook/list", h.handleListWebhooks)
+	admin(http.MethodPost, "/webhook/delete", h.handleDeleteWebhook)
+
+	user(http.MethodGet, "/stats", h.handleStats)
+
+	// /health is kept as an alias of /health/live for existing probes
+	public(http.MethodGet, "/health", h.handleLive)
+	public(http.MethodGet, "/health/live", h.handleLive)
+	public(http.MethodGet, "/health/ready", h.handleReady)
+
+	mux.Handle("GET /metrics", m.Handler())
 
-func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
 	}
-	w.WriteHeader(http.StatusOK)
+	return withRequestLogging(h.logger, mux), nil
 }
 
 func NewServer(addr string, handler http.Handler) *http.Server {
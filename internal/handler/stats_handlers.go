@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// GET /stats
+func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.svc.GetStats(r.Context())
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
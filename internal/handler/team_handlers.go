@@ -4,26 +4,29 @@ import (
 	"ReilBleem13/pull_requests_service/internal/domain"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/theartofdevel/logging"
 )
 
 // POST /team/add
 func (h *Handler) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req createTeamDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("invalid request body", logging.ErrAttr(err))
-		h.WriteError(w, domain.ErrInvalidRequest("invalid json payload"))
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
 		return
 	}
 
-	if err := h.svc.CreateTeam(r.Context(), req.TeamName, req.Members); err != nil {
-		h.WriteError(w, err)
+	settings := domain.TeamSettings{
+		ReviewerStrategy:  req.ReviewerStrategy,
+		RequiredReviewers: req.RequiredReviewers,
+		RequiredApprovals: req.RequiredApprovals,
+		FallbackTeams:     req.FallbackTeams,
+	}
+
+	if err := h.svc.CreateTeam(r.Context(), req.TeamName, req.Members, settings); err != nil {
+		h.WriteError(w, r, err)
 		return
 	}
 
@@ -31,15 +34,10 @@ func (h *Handler) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleGetTeam(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	teamName := r.URL.Query().Get("team_name")
 	users, err := h.svc.GetTeam(r.Context(), teamName)
 	if err != nil {
-		h.WriteError(w, err)
+		h.WriteError(w, r, err)
 		return
 	}
 
@@ -50,3 +48,133 @@ func (h *Handler) handleGetTeam(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, response)
 }
+
+// POST /team/update
+func (h *Handler) handleUpdateTeam(w http.ResponseWriter, r *http.Request) {
+	var req updateTeamDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	update := domain.TeamSettingsUpdate{
+		ReviewerStrategy:  req.ReviewerStrategy,
+		RequiredReviewers: req.RequiredReviewers,
+		RequiredApprovals: req.RequiredApprovals,
+		FallbackTeams:     req.FallbackTeams,
+	}
+
+	settings, err := h.svc.UpdateTeamSettings(r.Context(), req.TeamName, update)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"team": settings})
+}
+
+// POST /team/deactivateUsers
+func (h *Handler) handleDeactivateTeamUsers(w http.ResponseWriter, r *http.Request) {
+	var req deactivateTeamUsersDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	report, err := h.svc.DeactivateTeamUsers(r.Context(), req.TeamName, req.UserIDs)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// POST /team/addMembers
+func (h *Handler) handleAddTeamMembers(w http.ResponseWriter, r *http.Request) {
+	var req addTeamMembersDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	members, err := h.svc.AddTeamMembers(r.Context(), req.TeamName, req.Members)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, getTeamUsersDTO{
+		TeamName: req.TeamName,
+		Members:  members,
+	})
+}
+
+// POST /team/removeMembers
+func (h *Handler) handleRemoveTeamMembers(w http.ResponseWriter, r *http.Request) {
+	var req removeTeamMembersDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	report, err := h.svc.RemoveTeamMembers(r.Context(), req.TeamName, req.UserIDs, req.ReassignReviews)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// POST /team/moveMember
+func (h *Handler) handleMoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	var req moveTeamMemberDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	if err := h.svc.MoveTeamMember(r.Context(), req.UserID, req.FromTeam, req.ToTeam); err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, req)
+}
+
+// PUT /team/sync
+func (h *Handler) handleSyncTeam(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			h.WriteError(w, r, domain.ErrValidation(domain.FieldError{
+				Field:   "dry_run",
+				Rule:    domain.RuleType,
+				Message: "dry_run is invalid",
+			}))
+			return
+		}
+	}
+
+	var req syncTeamDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	diff, err := h.svc.SyncTeam(r.Context(), req.TeamName, req.Members, dryRun)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, diff)
+}
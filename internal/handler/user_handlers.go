@@ -10,45 +10,38 @@ import (
 
 // POST /user/setIsActive
 func (h *Handler) handleSetIsActive(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req setIsActiveDTO
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("invalid request body", logging.ErrAttr(err))
-		h.WriteError(w, domain.ErrInvalidRequest("invalid json payload"))
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
 		return
 	}
 
-	user, teamName, err := h.svc.SetIsActive(r.Context(), req.UserID, req.IsActive)
+	user, teamNames, err := h.svc.SetIsActive(r.Context(), req.UserID, req.IsActive)
 	if err != nil {
-		h.WriteError(w, err)
+		h.WriteError(w, r, err)
 		return
 	}
 
 	response := getSetUserDTO{
-		UserID:   user.UserID,
-		Username: user.Username,
-		TeamName: teamName,
-		IsActive: user.IsActive,
+		UserID:    user.UserID,
+		Username:  user.Username,
+		TeamNames: teamNames,
+		IsActive:  user.IsActive,
+	}
+	if len(teamNames) > 0 {
+		response.TeamName = teamNames[0]
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{"user": response})
 }
 
 func (h *Handler) handleGetReview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
 	userID := r.URL.Query().Get("user_id")
 
 	pullRequestsShort, err := h.svc.GetReview(r.Context(), userID)
 	if err != nil {
-		h.WriteError(w, err)
+		h.WriteError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/theartofdevel/logging"
+)
+
+// requestValidator checks requests against the OpenAPI specification before
+// they reach the handlers.
+type requestValidator struct {
+	spec    *openapi3.T
+	options *openapi3filter.Options
+}
+
+func newRequestValidator(spec []byte) (*requestValidator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("validate openapi spec: %w", err)
+	}
+
+	return &requestValidator{
+		spec: doc,
+		options: &openapi3filter.Options{
+			MultiError: true,
+			// defaults of the spec are applied by the service, and for partial
+			// updates like /team/update a missing field must stay missing
+			SkipSettingDefaults: true,
+			// tokens are checked by withAuth
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}, nil
+}
+
+// wrap validates the requests to next against the operation method path of
+// the spec.
+func (v *requestValidator) wrap(method, path string, next http.Handler) (http.Handler, error) {
+	pathItem := v.spec.Paths.Value(path)
+	if pathItem == nil || pathItem.GetOperation(method) == nil {
+		return nil, fmt.Errorf("openapi spec has no operation %s %s", method, path)
+	}
+
+	route := &routers.Route{
+		Spec:      v.spec,
+		Path:      path,
+		PathItem:  pathItem,
+		Method:    method,
+		Operation: pathItem.GetOperation(method),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request: r,
+			Route:   route,
+			Options: v.options,
+		})
+		if err != nil {
+			logging.L(r.Context()).Error("request does not match the api spec", logging.ErrAttr(err))
+			writeInvalidRequest(w, fieldErrors(err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	}), nil
+}
+
+func writeInvalidRequest(w http.ResponseWriter, details []domain.FieldError) {
+	appErr := domain.ErrValidation(details...).(*domain.AppError)
+	writeAPIResponse(w, http.StatusBadRequest, string(appErr.Code), appErr.Message, appErr.Details...)
+}
+
+// schemaRules maps the keywords of the spec to the rules reported by the
+// service for the same kind of mistake.
+var schemaRules = map[string]domain.Rule{
+	"required":             domain.RuleRequired,
+	"type":                 domain.RuleType,
+	"nullable":             domain.RuleType,
+	"enum":                 domain.RuleEnum,
+	"minimum":              domain.RuleRange,
+	"maximum":              domain.RuleRange,
+	"exclusiveMinimum":     domain.RuleRange,
+	"exclusiveMaximum":     domain.RuleRange,
+	"multipleOf":           domain.RuleRange,
+	"minItems":             domain.RuleRange,
+	"maxItems":             domain.RuleRange,
+	"minLength":            domain.RuleRange,
+	"maxLength":            domain.RuleMaxLength,
+	"uniqueItems":          domain.RuleUnique,
+	"format":               domain.RuleFormat,
+	"pattern":              domain.RuleFormat,
+	"properties":           domain.RuleUnknownField,
+	"additionalProperties": domain.RuleUnknownField,
+}
+
+// fieldErrors flattens the errors of openapi3filter into one entry per field.
+func fieldErrors(err error) []domain.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var details []domain.FieldError
+		for _, inner := range e {
+			details = append(details, fieldErrors(inner)...)
+		}
+		return details
+
+	case *openapi3filter.RequestError:
+		var details []domain.FieldError
+		var parseErr *openapi3filter.ParseError
+		switch {
+		case e.Err == nil:
+			details = []domain.FieldError{{Rule: domain.RuleInvalid, Message: e.Reason}}
+		case errors.Is(e.Err, openapi3filter.ErrInvalidRequired):
+			details = []domain.FieldError{{Rule: domain.RuleRequired, Message: e.Err.Error()}}
+		case errors.As(e.Err, &parseErr):
+			if e.RequestBody != nil {
+				details = []domain.FieldError{{Rule: domain.RuleFormat, Message: "invalid json payload"}}
+			} else {
+				details = []domain.FieldError{{Rule: domain.RuleType, Message: parseErr.Error()}}
+			}
+		default:
+			details = fieldErrors(e.Err)
+		}
+
+		if e.Parameter != nil {
+			for i := range details {
+				details[i].Field = e.Parameter.Name
+			}
+		}
+		return details
+
+	case *openapi3.SchemaError:
+		path := e.JSONPointer()
+		message := e.Reason
+
+		rule, ok := schemaRules[e.SchemaField]
+		if !ok {
+			rule = domain.RuleInvalid
+		}
+
+		switch e.SchemaField {
+		case "format":
+			// the reason of a format error quotes the whole regexp
+			message = fmt.Sprintf("value doesn't match the format %q", e.Schema.Format)
+		case "properties":
+			// an unknown property is reported on its object, name it instead
+			var property string
+			if _, scanErr := fmt.Sscanf(e.Reason, "property %q is unsupported", &property); scanErr == nil {
+				path = append(path, property)
+			}
+		}
+		return []domain.FieldError{{Field: fieldPath(path), Rule: rule, Message: message}}
+
+	default:
+		return []domain.FieldError{{Rule: domain.RuleInvalid, Message: err.Error()}}
+	}
+}
+
+// fieldPath turns a JSON pointer like [members 0 user_id] into members[0].user_id.
+func fieldPath(pointer []string) string {
+	var b strings.Builder
+	for _, part := range pointer {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
@@ -0,0 +1,197 @@
+package handler_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/auth"
+	"ReilBleem13/pull_requests_service/internal/handler"
+	"ReilBleem13/pull_requests_service/internal/metrics"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theartofdevel/logging"
+)
+
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(string, ...any) {}
+func (m *mockLogger) Info(string, ...any)  {}
+func (m *mockLogger) Warn(string, ...any)  {}
+func (m *mockLogger) Error(string, ...any) {}
+
+type errorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details []struct {
+			Field   string `json:"field"`
+			Rule    string `json:"rule"`
+			Message string `json:"message"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// newTestRouter builds the router over a service without repositories;
+// requests rejected by validation never reach it.
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	return newServiceRouter(t, service.NewService(nil, nil, nil, nil, &mockLogger{}))
+}
+
+func newServiceRouter(t *testing.T, svc *service.Service) http.Handler {
+	t.Helper()
+	return newMetricsRouter(t, svc, metrics.New(nil))
+}
+
+// newMetricsRouter builds the router reporting to m.
+func newMetricsRouter(t *testing.T, svc *service.Service, m *metrics.Metrics) http.Handler {
+	t.Helper()
+
+	router, err := handler.NewRouter(svc, logging.NewLogger(), m, handler.NewReadiness(nil, time.Second), newTestTokens(t))
+	require.NoError(t, err)
+	return router
+}
+
+// newTestTokens authenticates "tok-admin" as admin and "tok-user" as user.
+func newTestTokens(t *testing.T) auth.Authenticator {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, os.WriteFile(path, []byte("tok-admin admin root\ntok-user user u1\n"), 0o600))
+	tokens, err := auth.LoadStaticTokens(path)
+	require.NoError(t, err)
+	return tokens
+}
+
+func doRequest(router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer tok-admin")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := doRequest(router, http.MethodGet, "/team/add", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+
+	rec = doRequest(router, http.MethodDelete, "/team/get?team_name=backend", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+}
+
+func TestRouter_RequestValidation(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		fields map[string]string
+		rules  map[string]string
+	}{
+		{
+			name:   "unknown field",
+			method: http.MethodPost,
+			target: "/pullRequest/merge",
+			body:   `{"pull_request_id": "pr-1", "force": true}`,
+			fields: map[string]string{"force": "unsupported"},
+			rules:  map[string]string{"force": "unknown_field"},
+		},
+		{
+			name:   "wrong type",
+			method: http.MethodPost,
+			target: "/users/setIsActive",
+			body:   `{"user_id": "u1", "is_active": "yes"}`,
+			fields: map[string]string{"is_active": "boolean"},
+		},
+		{
+			name:   "all failures at once",
+			method: http.MethodPost,
+			target: "/team/add",
+			body:   `{"members": [{"user_id": 1, "username": "Alice"}], "required_reviewers": 0}`,
+			fields: map[string]string{
+				"team_name":            "missing",
+				"members[0].user_id":   "string",
+				"members[0].is_active": "missing",
+				"required_reviewers":   "at least 1",
+			},
+			rules: map[string]string{
+				"team_name":            "required",
+				"members[0].user_id":   "type",
+				"members[0].is_active": "required",
+				"required_reviewers":   "range",
+			},
+		},
+		{
+			name:   "missing query parameter",
+			method: http.MethodGet,
+			target: "/pullRequest/get",
+			fields: map[string]string{"pull_request_id": "required"},
+		},
+		{
+			name:   "invalid query parameters",
+			method: http.MethodGet,
+			target: "/pullRequest/list?limit=500&created_from=yesterday",
+			fields: map[string]string{"limit": "at most 100", "created_from": "date-time"},
+		},
+		{
+			name:   "invalid json",
+			method: http.MethodPost,
+			target: "/pullRequest/close",
+			body:   `{"pull_request_id":`,
+			fields: map[string]string{"": "invalid json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(router, tt.method, tt.target, tt.body)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var body errorBody
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.Equal(t, "INVALID_REQUEST", body.Error.Code)
+
+			got := make(map[string]string, len(body.Error.Details))
+			rules := make(map[string]string, len(body.Error.Details))
+			for _, detail := range body.Error.Details {
+				got[detail.Field] = detail.Message
+				rules[detail.Field] = detail.Rule
+				assert.NotEmpty(t, detail.Rule, "field %q", detail.Field)
+			}
+			require.Len(t, got, len(tt.fields), "details: %+v", body.Error.Details)
+			for field, message := range tt.fields {
+				assert.Contains(t, got[field], message, "field %q", field)
+			}
+			for field, rule := range tt.rules {
+				assert.Equal(t, rule, rules[field], "field %q", field)
+			}
+		})
+	}
+}
+
+func TestRouter_ValidationRunsAfterAuth(t *testing.T) {
+	router := newTestRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
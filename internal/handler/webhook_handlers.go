@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"encoding/json"
+	"net/http"
+
+	"github.com/theartofdevel/logging"
+)
+
+// POST /webhook/register
+func (h *Handler) handleRegisterWebhook(w http.ResponseWriter, r *http.Request) {
+	var req registerWebhookDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	webhook, err := h.svc.RegisterWebhook(r.Context(), req.TeamName, req.URL, req.Secret)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"webhook": webhook})
+}
+
+// GET /webhook/list
+func (h *Handler) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+
+	webhooks, err := h.svc.ListWebhooks(r.Context(), teamName)
+	if err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"team_name": teamName,
+		"webhooks":  webhooks,
+	})
+}
+
+// POST /webhook/delete
+func (h *Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	var req deleteWebhookDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logging.L(r.Context()).Error("invalid request body", logging.ErrAttr(err))
+		h.WriteError(w, r, domain.ErrInvalidRequest("invalid json payload"))
+		return
+	}
+
+	if err := h.svc.DeleteWebhook(r.Context(), req.WebhookID); err != nil {
+		h.WriteError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"webhook_id": req.WebhookID})
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pr_service"
+
+// Metrics owns the Prometheus registry of the service: HTTP traffic, business
+// counters and connection pool stats of the database.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	prsCreated          prometheus.Counter
+	prsMerged           prometheus.Counter
+	reviewersReassigned prometheus.Counter
+	noCandidate         prometheus.Counter
+}
+
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of served HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of served HTTP requests by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		prsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pull_requests_created_total",
+			Help:      "Number of created pull requests.",
+		}),
+		prsMerged: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pull_requests_merged_total",
+			Help:      "Number of pull requests moved to MERGED.",
+		}),
+		reviewersReassigned: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewers_reassigned_total",
+			Help:      "Number of reviews handed over to another reviewer, manually or when a reviewer leaves.",
+		}),
+		noCandidate: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reviewer_no_candidate_total",
+			Help:      "Number of reviews that could not be reassigned because no active candidate was found.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.prsCreated,
+		m.prsMerged,
+		m.reviewersReassigned,
+		m.noCandidate,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+	}
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTPRequest records a served request. route is the pattern the
+// handler was registered with, not the raw URL, to keep the label set bounded.
+func (m *Metrics) ObserveHTTPRequest(route, method string, status int, duration time.Duration) {
+	labels := prometheus.Labels{
+		"route":  route,
+		"method": method,
+		"status": strconv.Itoa(status),
+	}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+func (m *Metrics) PullRequestCreated() {
+	m.prsCreated.Inc()
+}
+
+func (m *Metrics) PullRequestMerged() {
+	m.prsMerged.Inc()
+}
+
+func (m *Metrics) ReviewersReassigned(count int) {
+	m.reviewersReassigned.Add(float64(count))
+}
+
+func (m *Metrics) NoCandidate(count int) {
+	m.noCandidate.Add(float64(count))
+}
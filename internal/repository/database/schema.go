@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SchemaVersion is the latest migration in migrations/ this build relies on.
+// Bump it together with every new migration.
+const SchemaVersion = 12
+
+// Ping checks that the database accepts connections.
+func (p *PostgresDB) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// CheckSchema verifies that the migrations applied by golang-migrate reached
+// SchemaVersion and that the last one didn't fail halfway. A newer schema is
+// accepted, so that instances of the previous release keep serving while a
+// rolling update migrates the database ahead of them.
+func (p *PostgresDB) CheckSchema(ctx context.Context) error {
+	versionQuery := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+	var (
+		version int64
+		dirty   bool
+	)
+	err := p.db.QueryRowxContext(ctx, versionQuery).Scan(&version, &dirty)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no migrations applied, expected version %d", SchemaVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected at least %d", version, SchemaVersion)
+	}
+	return nil
+}
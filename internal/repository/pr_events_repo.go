@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+func (p *PullRequestRepository) GetHistory(ctx context.Context, prID string) ([]domain.PullRequestEvent, error) {
+	checkQuery := `
+		SELECT EXISTS(
+			SELECT 1 FROM pull_requests
+			WHERE pull_request_id = $1
+		)
+	`
+
+	var exists bool
+	if err := p.db.GetContext(ctx, &exists, checkQuery, prID); err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("pull_request is not exits: %w", domain.ErrNotFound())
+	}
+
+	getQuery := `
+		SELECT
+			event_id,
+			pull_request_id,
+			event_type,
+			actor_id,
+			reviewer_id,
+			old_reviewer_id,
+			is_active,
+			decision,
+			created_at
+		FROM pull_request_events
+		WHERE pull_request_id = $1
+		ORDER BY event_id
+	`
+
+	events := make([]domain.PullRequestEvent, 0)
+	if err := p.db.SelectContext(ctx, &events, getQuery, prID); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// recordEvent appends an entry to the history of a pull request. The actor is
+// taken from the context; empty ids are stored as NULL.
+func recordEvent(
+	ctx context.Context,
+	tx execer,
+	prID string,
+	eventType domain.PullRequestEventType,
+	reviewerID, oldReviewerID string,
+) error {
+	insertQuery := `
+		INSERT INTO pull_request_events (pull_request_id, event_type, actor_id, reviewer_id, old_reviewer_id)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''))
+	`
+
+	_, err := tx.ExecContext(ctx, insertQuery,
+		prID, string(eventType), domain.ActorFromContext(ctx), reviewerID, oldReviewerID,
+	)
+	return err
+}
+
+// recordActivityChanged adds an ACTIVITY_CHANGED event to every open pull
+// request reviewed by one of the given users.
+func recordActivityChanged(ctx context.Context, tx execer, userIDs []string, isActive bool) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	insertQuery := `
+		INSERT INTO pull_request_events (pull_request_id, event_type, actor_id, reviewer_id, is_active)
+		SELECT prr.pull_request_id, 'ACTIVITY_CHANGED', NULLIF($3, ''), prr.user_id, $2
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'OPEN' AND prr.user_id = ANY($1)
+		ORDER BY prr.pull_request_id, prr.user_id
+	`
+
+	_, err := tx.ExecContext(ctx, insertQuery, pq.Array(userIDs), isActive, domain.ActorFromContext(ctx))
+	return err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"fmt"
+	"strings"
+)
+
+func (p *PullRequestRepository) List(ctx context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	where := func(condition string, values ...any) {
+		placeholders := make([]any, 0, len(values))
+		for _, v := range values {
+			args = append(args, v)
+			placeholders = append(placeholders, len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.Status != "" {
+		where(`pr.status = $%d`, filter.Status)
+	}
+	if filter.AuthorID != "" {
+		where(`pr.author_id = $%d`, filter.AuthorID)
+	}
+	if filter.ReviewerID != "" {
+		where(`EXISTS (
+			SELECT 1 FROM pull_request_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = $%d
+		)`, filter.ReviewerID)
+	}
+	if filter.TeamName != "" {
+		where(`pr.team_name = $%d`, filter.TeamName)
+	}
+	if filter.CreatedFrom != nil {
+		where(`pr.created_at >= $%d`, *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		where(`pr.created_at < $%d`, *filter.CreatedTo)
+	}
+	if filter.MergedFrom != nil {
+		where(`pr.merged_at >= $%d`, *filter.MergedFrom)
+	}
+	if filter.MergedTo != nil {
+		where(`pr.merged_at < $%d`, *filter.MergedTo)
+	}
+	if filter.After != nil {
+		where(`(pr.created_at, pr.pull_request_id) > ($%d, $%d)`, filter.After.CreatedAt, filter.After.PullRequestID)
+	}
+
+	listQuery := `
+		SELECT
+			pr.pull_request_id,
+			pr.pull_request_name,
+			pr.author_id,
+			pr.team_name,
+			pr.status,
+			pr.created_at,
+			pr.merged_at,
+			pr.closed_at
+		FROM pull_requests pr
+	`
+	if len(conditions) > 0 {
+		listQuery += "WHERE " + strings.Join(conditions, "\n\t\t\tAND ")
+	}
+
+	args = append(args, filter.Limit)
+	listQuery += fmt.Sprintf("\n\t\tORDER BY pr.created_at, pr.pull_request_id\n\t\tLIMIT $%d", len(args))
+
+	pullRequests := make([]domain.PullRequest, 0)
+	if err := p.db.SelectContext(ctx, &pullRequests, listQuery, args...); err != nil {
+		return nil, err
+	}
+
+	if err := loadReviewers(ctx, p.db, pullRequests); err != nil {
+		return nil, err
+	}
+	return pullRequests, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -20,7 +21,17 @@ func NewPullRequestRepository(db *sqlx.DB) *PullRequestRepository {
 	}
 }
 
-func (p *PullRequestRepository) Create(ctx context.Context, prID, prName, authorID string, assignedUsers []string) error {
+// Create stores the PR in the given status with its reviewers; a draft is
+// stored without reviewers. When idempotency is not nil the
+// record is saved in the same transaction; it goes first, so a concurrent retry
+// with the same key waits for this transaction and then fails on the key.
+func (p *PullRequestRepository) Create(
+	ctx context.Context,
+	prID, prName, authorID, teamName string,
+	status domain.PRStatus,
+	reviewers []domain.Reviewer,
+	idempotency *domain.IdempotencyRecord,
+) error {
 	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return err
@@ -33,11 +44,27 @@ func (p *PullRequestRepository) Create(ctx context.Context, prID, prName, author
 		}
 	}()
 
+	if idempotency != nil {
+		saveKeyQuery := `
+			INSERT INTO idempotency_keys (actor_id, idempotency_key, request_hash, pull_request_id, response)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		_, err = tx.ExecContext(ctx, saveKeyQuery,
+			idempotency.ActorID, idempotency.Key, idempotency.RequestHash, prID, string(idempotency.Response),
+		)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return domain.ErrIdempotencyKeyReused()
+			}
+			return err
+		}
+	}
+
 	createPRQuery := `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status)
+		VALUES ($1, $2, $3, $4, $5)
 	`
-	_, err = tx.ExecContext(ctx, createPRQuery, prID, prName, authorID)
+	_, err = tx.ExecContext(ctx, createPRQuery, prID, prName, authorID, teamName, string(status))
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return domain.ErrPRExists()
@@ -45,17 +72,52 @@ func (p *PullRequestRepository) Create(ctx context.Context, prID, prName, author
 		return err
 	}
 
-	insertReviewerQuery := `
-		INSERT INTO pull_request_reviewers (pull_request_id, user_id)
-		VALUES ($1, $2)
+	err = recordEvent(ctx, tx, prID, domain.PullRequestEventCreated, "", "")
+	if err != nil {
+		return err
+	}
+
+	err = assignReviewers(ctx, tx, prID, reviewers)
+	if err != nil {
+		return err
+	}
+
+	err = enqueueWebhooks(ctx, tx, prID, domain.WebhookEventPRCreated, nil)
+	return err
+}
+
+// GetIdempotencyRecord returns the record the actor saved for key or nil if
+// the actor hasn't used the key.
+func (p *PullRequestRepository) GetIdempotencyRecord(ctx context.Context, actorID, key string) (*domain.IdempotencyRecord, error) {
+	getQuery := `
+		SELECT actor_id, idempotency_key, request_hash, response
+		FROM idempotency_keys
+		WHERE actor_id = $1 AND idempotency_key = $2
 	`
-	for _, assignedUser := range assignedUsers {
-		_, err = tx.ExecContext(ctx, insertReviewerQuery, prID, assignedUser)
-		if err != nil {
-			return err
+
+	var record domain.IdempotencyRecord
+	if err := p.db.GetContext(ctx, &record, getQuery, actorID, key); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
+		return nil, err
 	}
-	return nil
+	return &record, nil
+}
+
+// DeleteIdempotencyRecords removes the records saved before createdBefore and
+// returns how many were removed.
+func (p *PullRequestRepository) DeleteIdempotencyRecords(ctx context.Context, createdBefore time.Time) (int64, error) {
+	deleteQuery := `
+		DELETE FROM idempotency_keys
+		WHERE created_at < $1
+	`
+
+	result, err := p.db.ExecContext(ctx, deleteQuery, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 func (p *PullRequestRepository) GetActiveTeamMembers(ctx context.Context, teamName, authorID string) ([]domain.User, error) {
@@ -66,6 +128,7 @@ func (p *PullRequestRepository) GetActiveTeamMembers(ctx context.Context, teamNa
 		WHERE tm.team_name = $1 
 			AND u.is_active = true 
 			AND u.user_id != $2
+		ORDER BY u.user_id
 	`
 
 	var users []domain.User
@@ -75,10 +138,39 @@ func (p *PullRequestRepository) GetActiveTeamMembers(ctx context.Context, teamNa
 	return users, nil
 }
 
-func (p *PullRequestRepository) Merge(ctx context.Context, prID string) error {
+func (p *PullRequestRepository) CountOpenAssignments(ctx context.Context, userIDs []string) (map[string]int, error) {
+	countQuery := `
+		SELECT prr.user_id, COUNT(*) AS open_assignments
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		WHERE pr.status = 'OPEN' AND prr.user_id = ANY($1)
+		GROUP BY prr.user_id
+	`
+
+	var rows []struct {
+		UserID          string `db:"user_id"`
+		OpenAssignments int    `db:"open_assignments"`
+	}
+	if err := p.db.SelectContext(ctx, &rows, countQuery, pq.Array(userIDs)); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(userIDs))
+	for _, row := range rows {
+		counts[row.UserID] = row.OpenAssignments
+	}
+	return counts, nil
+}
+
+// Merge marks the PR as MERGED and returns it. The row is locked for the
+// duration of the transaction, so of several concurrent calls exactly one
+// reports merged = true; the others see the PR already merged and change nothing.
+// An open PR is merged only if it has the approvals required by its team;
+// drafts and closed PRs can't be merged.
+func (p *PullRequestRepository) Merge(ctx context.Context, prID string) (pullRequest *domain.PullRequest, merged bool, err error) {
 	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 	defer func() {
 		if err != nil {
@@ -88,34 +180,77 @@ func (p *PullRequestRepository) Merge(ctx context.Context, prID string) error {
 		}
 	}()
 
-	checkQuery := `
-		SELECT EXISTS(
-			SELECT 1 FROM pull_requests
+	pr, err := lockPullRequest(ctx, tx, prID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if pr.Status != domain.PRStatusMerged && !pr.Status.CanTransitionTo(domain.PRStatusMerged) {
+		err = domain.ErrInvalidTransition(pr.Status, domain.PRStatusMerged)
+		return nil, false, err
+	}
+
+	if pr.Status == domain.PRStatusOpen {
+		// проверка под блокировкой PR: решения ревьюверов не меняются до конца транзакции
+		if err = checkApprovals(ctx, tx, prID, pr.TeamName); err != nil {
+			return nil, false, err
+		}
+
+		updateQuery := `
+			UPDATE pull_requests
+			SET status = 'MERGED',
+				merged_at = NOW()
 			WHERE pull_request_id = $1
-		)
-	`
+			RETURNING status, merged_at
+		`
 
-	var exists bool
-	if err := tx.GetContext(ctx, &exists, checkQuery, prID); err != nil {
-		return err
+		if err = tx.GetContext(ctx, &pr, updateQuery, prID); err != nil {
+			return nil, false, err
+		}
+
+		if err = recordEvent(ctx, tx, prID, domain.PullRequestEventMerged, "", ""); err != nil {
+			return nil, false, err
+		}
+
+		if err = enqueueWebhooks(ctx, tx, prID, domain.WebhookEventPRMerged, nil); err != nil {
+			return nil, false, err
+		}
+		merged = true
 	}
 
-	if !exists {
-		return fmt.Errorf("pull request is empty: %w", domain.ErrNotFound())
+	prs := []domain.PullRequest{*pr}
+	if err = loadReviewers(ctx, tx, prs); err != nil {
+		return nil, false, err
 	}
+	return &prs[0], merged, nil
+}
 
-	updateQuery := `
-		UPDATE pull_requests 
-		SET status = 'MERGED', 
-			merged_at = NOW()
-		WHERE pull_request_id = $1 AND status = 'OPEN'
+// lockPullRequest reads the PR and locks its row until the end of tx. Every
+// status change and review decision takes this lock first.
+func lockPullRequest(ctx context.Context, tx *sqlx.Tx, prID string) (*domain.PullRequest, error) {
+	lockQuery := `
+		SELECT
+			pull_request_id,
+			pull_request_name,
+			author_id,
+			team_name,
+			status,
+			created_at,
+			merged_at,
+			closed_at
+		FROM pull_requests
+		WHERE pull_request_id = $1
+		FOR UPDATE
 	`
 
-	_, err = tx.ExecContext(ctx, updateQuery, prID)
-	if err != nil {
-		return err
+	var pr domain.PullRequest
+	if err := tx.GetContext(ctx, &pr, lockQuery, prID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("pull_request is not exits: %w", domain.ErrNotFound())
+		}
+		return nil, err
 	}
-	return nil
+	return &pr, nil
 }
 
 func (p *PullRequestRepository) GetPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
@@ -138,8 +273,11 @@ func (p *PullRequestRepository) GetPullRequest(ctx context.Context, prID string)
 			pull_request_id,
 			pull_request_name,
 			author_id,
+			team_name,
 			status,
-			merged_at
+			created_at,
+			merged_at,
+			closed_at
 		FROM pull_requests
 		WHERE pull_request_id = $1
 	`
@@ -152,18 +290,20 @@ func (p *PullRequestRepository) GetPullRequest(ctx context.Context, prID string)
 	}
 
 	getQuery := `
-		SELECT prr.user_id 
+		SELECT prr.user_id, COALESCE(prr.team_name, '') AS team_name, prr.assigned_at, prr.decision, prr.decided_at
 		FROM pull_request_reviewers prr
 		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
 		WHERE prr.pull_request_id = $1 
+		ORDER BY prr.assigned_at, prr.user_id
 	`
 	// убрать проверку на pr.author_id (AND prr.user_id != pr.author_id)
-	var users []string
-	if err := tx.SelectContext(ctx, &users, getQuery, prID); err != nil {
+	reviewers := make([]domain.Reviewer, 0)
+	if err := tx.SelectContext(ctx, &reviewers, getQuery, prID); err != nil {
 		return nil, err
 	}
 
-	pullRequest.AssignedReviewers = users
+	pullRequest.Reviewers = reviewers
+	pullRequest.AssignedReviewers = domain.ReviewerIDs(reviewers)
 	return &pullRequest, nil
 }
 
@@ -191,14 +331,115 @@ func (p *PullRequestRepository) GetPullRequestByID(ctx context.Context, userID s
 	return pullRequests, nil
 }
 
-func (p *PullRequestRepository) ReAssign(ctx context.Context, prID, oldReviewerID, newReviewerID string) error {
+func (p *PullRequestRepository) GetOpenPullRequestsByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error) {
+	getQuery := `
+		SELECT
+			pr.pull_request_id,
+			pr.pull_request_name,
+			pr.author_id,
+			pr.team_name,
+			pr.status,
+			pr.created_at
+		FROM pull_requests pr
+		WHERE pr.status = 'OPEN'
+			AND EXISTS (
+				SELECT 1 FROM pull_request_reviewers prr
+				WHERE prr.pull_request_id = pr.pull_request_id
+					AND prr.user_id = ANY($1)
+			)
+		ORDER BY pr.created_at, pr.pull_request_id
+	`
+
+	pullRequests := make([]domain.PullRequest, 0)
+	if err := p.db.SelectContext(ctx, &pullRequests, getQuery, pq.Array(userIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := loadReviewers(ctx, p.db, pullRequests); err != nil {
+		return nil, err
+	}
+	return pullRequests, nil
+}
+
+// loadReviewers fills reviewers of all given pull requests with a single query.
+func loadReviewers(ctx context.Context, q sqlx.QueryerContext, pullRequests []domain.PullRequest) error {
+	if len(pullRequests) == 0 {
+		return nil
+	}
+
+	prIDs := make([]string, 0, len(pullRequests))
+	for _, pr := range pullRequests {
+		prIDs = append(prIDs, pr.PullRequestID)
+	}
+
+	getQuery := `
+		SELECT pull_request_id, user_id, COALESCE(team_name, '') AS team_name, assigned_at, decision, decided_at
+		FROM pull_request_reviewers
+		WHERE pull_request_id = ANY($1)
+		ORDER BY assigned_at, user_id
+	`
+
+	var rows []struct {
+		PullRequestID string `db:"pull_request_id"`
+		domain.Reviewer
+	}
+	if err := sqlx.SelectContext(ctx, q, &rows, getQuery, pq.Array(prIDs)); err != nil {
+		return err
+	}
+
+	byPR := make(map[string][]domain.Reviewer, len(pullRequests))
+	for _, row := range rows {
+		byPR[row.PullRequestID] = append(byPR[row.PullRequestID], row.Reviewer)
+	}
+
+	for i := range pullRequests {
+		reviewers := byPR[pullRequests[i].PullRequestID]
+		if reviewers == nil {
+			reviewers = make([]domain.Reviewer, 0)
+		}
+		pullRequests[i].Reviewers = reviewers
+		pullRequests[i].AssignedReviewers = domain.ReviewerIDs(reviewers)
+	}
+	return nil
+}
+
+func (p *PullRequestRepository) ReAssign(ctx context.Context, prID, oldReviewerID string, newReviewer domain.Reviewer) error {
+	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// та же блокировка, что и в Merge: сброс решения старого ревьювера не должен
+	// пересечься с проверкой апрувов, а смерженный тем временем PR не трогаем
+	pr, err := lockPullRequest(ctx, tx, prID)
+	if err != nil {
+		return err
+	}
+
+	switch pr.Status {
+	case domain.PRStatusOpen:
+	case domain.PRStatusMerged:
+		err = domain.ErrPRMerged()
+		return err
+	default:
+		err = domain.ErrReassignOnClosedPR(pr.Status)
+		return err
+	}
+
 	reassignQuery := `
-		UPDATE pull_request_reviewers 
-		SET user_id = $3
+		UPDATE pull_request_reviewers
+		SET user_id = $3, team_name = $4, assigned_at = NOW(), decision = NULL, decided_at = NULL
 		WHERE pull_request_id = $1 AND user_id = $2
 	`
 
-	res, err := p.db.ExecContext(ctx, reassignQuery, prID, oldReviewerID, newReviewerID)
+	res, err := tx.ExecContext(ctx, reassignQuery, prID, oldReviewerID, newReviewer.UserID, newReviewer.TeamName)
 	if err != nil {
 		return err
 	}
@@ -209,7 +450,18 @@ func (p *PullRequestRepository) ReAssign(ctx context.Context, prID, oldReviewerI
 	}
 
 	if n == 0 {
-		return fmt.Errorf("pull_request was not found: %w", domain.ErrNotFound())
+		err = fmt.Errorf("pull_request was not found: %w", domain.ErrNotFound())
+		return err
 	}
-	return nil
+
+	err = recordEvent(ctx, tx, prID, domain.PullRequestEventReassigned, newReviewer.UserID, oldReviewerID)
+	if err != nil {
+		return err
+	}
+
+	err = enqueueWebhooks(ctx, tx, prID, domain.WebhookEventPRReassigned, map[string]any{
+		"old_reviewer_id": oldReviewerID,
+		"new_reviewer_id": newReviewer.UserID,
+	})
+	return err
 }
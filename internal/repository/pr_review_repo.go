@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SubmitReview stores the reviewer's decision on an open PR, replacing the
+// previous one, and returns the PR with its reviewers.
+func (p *PullRequestRepository) SubmitReview(
+	ctx context.Context,
+	prID, reviewerID string,
+	decision domain.ReviewDecision,
+) (pullRequest *domain.PullRequest, err error) {
+	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	// та же блокировка, что и в Merge, чтобы решение не менялось во время мержа
+	pr, err := lockPullRequest(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Status == domain.PRStatusMerged {
+		err = domain.ErrReviewOnMergedPR()
+		return nil, err
+	}
+
+	reviewQuery := `
+		UPDATE pull_request_reviewers
+		SET decision = $3, decided_at = NOW()
+		WHERE pull_request_id = $1 AND user_id = $2
+	`
+
+	res, err := tx.ExecContext(ctx, reviewQuery, prID, reviewerID, string(decision))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		err = domain.ErrNotAssigned()
+		return nil, err
+	}
+
+	recordQuery := `
+		INSERT INTO pull_request_events (pull_request_id, event_type, actor_id, reviewer_id, decision)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+	`
+
+	_, err = tx.ExecContext(ctx, recordQuery,
+		prID, string(domain.PullRequestEventReviewed), domain.ActorFromContext(ctx), reviewerID, string(decision),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	prs := []domain.PullRequest{*pr}
+	if err = loadReviewers(ctx, tx, prs); err != nil {
+		return nil, err
+	}
+	return &prs[0], nil
+}
+
+// checkApprovals fails with NOT_APPROVED if the team requires approvals and the
+// PR has fewer of them or a reviewer has requested changes.
+func checkApprovals(ctx context.Context, q sqlx.QueryerContext, prID, teamName string) error {
+	checkQuery := `
+		SELECT
+			t.required_approvals,
+			COUNT(prr.user_id) FILTER (WHERE prr.decision = 'APPROVED')          AS approvals,
+			COUNT(prr.user_id) FILTER (WHERE prr.decision = 'CHANGES_REQUESTED') AS changes_requested
+		FROM teams t
+		LEFT JOIN pull_request_reviewers prr ON prr.pull_request_id = $1
+		WHERE t.team_name = $2
+		GROUP BY t.required_approvals
+	`
+
+	var row struct {
+		RequiredApprovals int `db:"required_approvals"`
+		Approvals         int `db:"approvals"`
+		ChangesRequested  int `db:"changes_requested"`
+	}
+	if err := sqlx.GetContext(ctx, q, &row, checkQuery, prID, teamName); err != nil {
+		return err
+	}
+
+	if row.RequiredApprovals == 0 {
+		return nil
+	}
+
+	if row.ChangesRequested > 0 {
+		return domain.ErrNotApproved("reviewers requested changes")
+	}
+
+	if row.Approvals < row.RequiredApprovals {
+		return domain.ErrNotApproved(fmt.Sprintf("PR has %d of %d required approvals", row.Approvals, row.RequiredApprovals))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+)
+
+func (p *PullRequestRepository) GetReviewerStats(ctx context.Context) ([]domain.ReviewerStats, error) {
+	getQuery := `
+		SELECT
+			u.user_id,
+			u.username,
+			COUNT(*) FILTER (WHERE pr.status = 'OPEN')   AS open_assignments,
+			COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged_assignments
+		FROM pull_request_reviewers prr
+		JOIN users u ON u.user_id = prr.user_id
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		GROUP BY u.user_id, u.username
+		ORDER BY open_assignments DESC, u.user_id
+	`
+
+	stats := make([]domain.ReviewerStats, 0)
+	if err := p.db.SelectContext(ctx, &stats, getQuery); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (p *PullRequestRepository) GetAuthorStats(ctx context.Context) ([]domain.AuthorStats, error) {
+	getQuery := `
+		SELECT
+			author_id,
+			COUNT(*)                                  AS pull_requests,
+			COUNT(*) FILTER (WHERE status = 'OPEN')   AS open_pull_requests,
+			COUNT(*) FILTER (WHERE status = 'MERGED') AS merged_pull_requests
+		FROM pull_requests
+		GROUP BY author_id
+		ORDER BY pull_requests DESC, author_id
+	`
+
+	stats := make([]domain.AuthorStats, 0)
+	if err := p.db.SelectContext(ctx, &stats, getQuery); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (p *PullRequestRepository) GetTeamStats(ctx context.Context) ([]domain.TeamStats, error) {
+	getQuery := `
+		SELECT
+			t.team_name,
+			COUNT(pr.pull_request_id)                                     AS pull_requests,
+			COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'OPEN')   AS open_pull_requests,
+			COUNT(pr.pull_request_id) FILTER (WHERE pr.status = 'MERGED') AS merged_pull_requests,
+			(
+				SELECT COUNT(*)
+				FROM pull_request_reviewers prr
+				JOIN pull_requests tpr ON tpr.pull_request_id = prr.pull_request_id
+				WHERE tpr.team_name = t.team_name
+			) AS assignments
+		FROM teams t
+		LEFT JOIN pull_requests pr ON pr.team_name = t.team_name
+		GROUP BY t.team_name
+		ORDER BY t.team_name
+	`
+
+	stats := make([]domain.TeamStats, 0)
+	if err := p.db.SelectContext(ctx, &stats, getQuery); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (p *PullRequestRepository) GetAverageMergeTime(ctx context.Context) (float64, error) {
+	getQuery := `
+		SELECT COALESCE(EXTRACT(EPOCH FROM AVG(merged_at - created_at)), 0)
+		FROM pull_requests
+		WHERE status = 'MERGED' AND merged_at IS NOT NULL
+	`
+
+	var seconds float64
+	if err := p.db.GetContext(ctx, &seconds, getQuery); err != nil {
+		return 0, err
+	}
+	return seconds, nil
+}
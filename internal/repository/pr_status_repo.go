@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"database/sql"
+)
+
+// ChangeStatus moves the PR to status to if the transition table allows it
+// from the status the PR has under the row lock and, if from is not empty,
+// that status is from. Moving to OPEN assigns the given reviewers, moving to
+// CLOSED releases all reviewers of the PR.
+func (p *PullRequestRepository) ChangeStatus(
+	ctx context.Context,
+	prID string,
+	from, to domain.PRStatus,
+	reviewers []domain.Reviewer,
+) (pullRequest *domain.PullRequest, err error) {
+	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	pr, err := lockPullRequest(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pr.Status.CanTransitionTo(to) || (from != "" && pr.Status != from) {
+		err = domain.ErrInvalidTransition(pr.Status, to)
+		return nil, err
+	}
+
+	event := domain.PullRequestEventClosed
+	switch {
+	case to == domain.PRStatusOpen && pr.Status == domain.PRStatusDraft:
+		event = domain.PullRequestEventReady
+	case to == domain.PRStatusOpen:
+		event = domain.PullRequestEventReopened
+	}
+
+	updateQuery := `
+		UPDATE pull_requests
+		SET status = $2,
+			closed_at = CASE WHEN $2 = 'CLOSED' THEN NOW() END
+		WHERE pull_request_id = $1
+		RETURNING status, closed_at
+	`
+
+	if err = tx.GetContext(ctx, pr, updateQuery, prID, string(to)); err != nil {
+		return nil, err
+	}
+
+	if err = recordEvent(ctx, tx, prID, event, "", ""); err != nil {
+		return nil, err
+	}
+
+	if to == domain.PRStatusClosed {
+		releaseQuery := `DELETE FROM pull_request_reviewers WHERE pull_request_id = $1`
+		if _, err = tx.ExecContext(ctx, releaseQuery, prID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err = assignReviewers(ctx, tx, prID, reviewers); err != nil {
+			return nil, err
+		}
+	}
+
+	prs := []domain.PullRequest{*pr}
+	if err = loadReviewers(ctx, tx, prs); err != nil {
+		return nil, err
+	}
+	return &prs[0], nil
+}
+
+// assignReviewers adds reviewers to the PR and records an ASSIGNED event for each.
+func assignReviewers(ctx context.Context, tx execer, prID string, reviewers []domain.Reviewer) error {
+	insertReviewerQuery := `
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name)
+		VALUES ($1, $2, $3)
+	`
+
+	for _, reviewer := range reviewers {
+		if _, err := tx.ExecContext(ctx, insertReviewerQuery, prID, reviewer.UserID, reviewer.TeamName); err != nil {
+			return err
+		}
+
+		if err := recordEvent(ctx, tx, prID, domain.PullRequestEventAssigned, reviewer.UserID, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// AddMembers adds users to an existing team, creating the users that don't
+// exist yet and updating the username and activity of those that do. Users who
+// are already members keep their original joined_at.
+func (t *TeamRepository) AddMembers(ctx context.Context, teamName string, users []domain.User) error {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	lockTeamQuery := `SELECT team_name FROM teams WHERE team_name = $1 FOR SHARE`
+
+	var lockedTeam string
+	if err = tx.GetContext(ctx, &lockedTeam, lockTeamQuery, teamName); err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("team is not exist: %w", domain.ErrNotFound())
+		}
+		return err
+	}
+
+	err = upsertUsers(ctx, tx, users)
+	if err != nil {
+		return err
+	}
+
+	addMemberQuery := `
+		INSERT INTO team_members (team_name, user_id, joined_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, team_name) DO NOTHING
+	`
+
+	for _, user := range users {
+		_, err = tx.ExecContext(ctx, addMemberQuery, teamName, user.UserID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveMembers removes users from the team and applies the planned
+// reassignments of their open reviews in one transaction. It returns the
+// reassignments that were applied.
+func (t *TeamRepository) RemoveMembers(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	reassignments []domain.Reassignment,
+) ([]domain.Reassignment, error) {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	removeQuery := `
+		DELETE FROM team_members
+		WHERE team_name = $1 AND user_id = ANY($2)
+		RETURNING user_id
+	`
+
+	var removed []string
+	err = tx.SelectContext(ctx, &removed, removeQuery, teamName, pq.Array(userIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(removed) != len(userIDs) {
+		err = fmt.Errorf("some users are not members of team %s: %w", teamName, domain.ErrNotFound())
+		return nil, err
+	}
+
+	applied, err := applyReassignments(ctx, tx, reassignments)
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// MoveMember moves the user from one team to another; joined_at is set to the
+// time of the move.
+func (t *TeamRepository) MoveMember(ctx context.Context, userID, fromTeam, toTeam string) error {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	removeQuery := `DELETE FROM team_members WHERE team_name = $1 AND user_id = $2`
+
+	res, err := tx.ExecContext(ctx, removeQuery, fromTeam, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		err = fmt.Errorf("user %s is not a member of team %s: %w", userID, fromTeam, domain.ErrNotFound())
+		return err
+	}
+
+	addMemberQuery := `
+		INSERT INTO team_members (team_name, user_id, joined_at)
+		VALUES ($1, $2, NOW())
+	`
+
+	_, err = tx.ExecContext(ctx, addMemberQuery, toTeam, userID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23503":
+				err = fmt.Errorf("team %s is not exist: %w", toTeam, domain.ErrNotFound())
+			case "23505":
+				err = domain.ErrInvalidRequest("user is already a member of to_team")
+			}
+		}
+		return err
+	}
+	return nil
+}
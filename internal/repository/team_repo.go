@@ -2,8 +2,11 @@ package repository
 
 import (
 	"ReilBleem13/pull_requests_service/internal/domain"
+	"cmp"
 	"context"
 	"database/sql"
+	"fmt"
+	"slices"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -19,8 +22,8 @@ func NewTeamRepository(db *sqlx.DB) *TeamRepository {
 	}
 }
 
-func (t *TeamRepository) Create(ctx context.Context, teamName string, users []domain.User) error {
-	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{})
+func (t *TeamRepository) Create(ctx context.Context, teamName string, users []domain.User, settings domain.TeamSettings) error {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return err
 	}
@@ -33,8 +36,11 @@ func (t *TeamRepository) Create(ctx context.Context, teamName string, users []do
 	}()
 
 	createTeamQuery := `
-		INSERT INTO teams (team_name) VALUES ($1)`
-	_, err = tx.ExecContext(ctx, createTeamQuery, teamName)
+		INSERT INTO teams (team_name, reviewer_strategy, required_reviewers, required_approvals)
+		VALUES ($1, $2, $3, $4)`
+	_, err = tx.ExecContext(ctx, createTeamQuery,
+		teamName, settings.ReviewerStrategy, settings.RequiredReviewers, settings.RequiredApprovals,
+	)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			return domain.ErrTeamExists()
@@ -42,11 +48,10 @@ func (t *TeamRepository) Create(ctx context.Context, teamName string, users []do
 		return err
 	}
 
-	createUserQuery := `
-		INSERT INTO users (user_id, username, is_active)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (user_id) DO NOTHING
-	`
+	err = upsertUsers(ctx, tx, users)
+	if err != nil {
+		return err
+	}
 
 	createTeamMember := `
 		INSERT INTO team_members (user_id, team_name)
@@ -55,18 +60,14 @@ func (t *TeamRepository) Create(ctx context.Context, teamName string, users []do
 	`
 
 	for _, user := range users {
-		_, err := tx.ExecContext(ctx, createUserQuery, user.UserID, user.Username, user.IsActive)
-		if err != nil {
-			return err
-		}
-
 		_, err = tx.ExecContext(ctx, createTeamMember, user.UserID, teamName)
 		if err != nil {
 			return err
 		}
 	}
-	// добавить проверку если уже существует
-	return nil
+
+	err = replaceFallbackTeams(ctx, tx, teamName, settings.FallbackTeams)
+	return err
 }
 
 func (t *TeamRepository) Get(ctx context.Context, teamName string) ([]domain.User, error) {
@@ -95,3 +96,294 @@ func (t *TeamRepository) Get(ctx context.Context, teamName string) ([]domain.Use
 	}
 	return teamMembers, nil
 }
+
+func (t *TeamRepository) GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	getQuery := `
+		SELECT team_name, reviewer_strategy, required_reviewers, required_approvals
+		FROM teams
+		WHERE team_name = $1
+	`
+
+	var settings domain.TeamSettings
+	if err := t.db.GetContext(ctx, &settings, getQuery, teamName); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("team is not exist: %w", domain.ErrNotFound())
+		}
+		return nil, err
+	}
+
+	fallbackTeams, err := getFallbackTeams(ctx, t.db, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.FallbackTeams = fallbackTeams
+	return &settings, nil
+}
+
+func (t *TeamRepository) UpdateSettings(ctx context.Context, teamName string, update domain.TeamSettingsUpdate) (*domain.TeamSettings, error) {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	updateQuery := `
+		UPDATE teams
+		SET reviewer_strategy = COALESCE($2, reviewer_strategy),
+			required_reviewers = COALESCE($3, required_reviewers),
+			required_approvals = COALESCE($4, required_approvals),
+			updated_at = NOW()
+		WHERE team_name = $1
+		RETURNING team_name, reviewer_strategy, required_reviewers, required_approvals
+	`
+
+	var settings domain.TeamSettings
+	err = tx.GetContext(ctx, &settings, updateQuery,
+		teamName, update.ReviewerStrategy, update.RequiredReviewers, update.RequiredApprovals,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("team is not exist: %w", domain.ErrNotFound())
+		}
+		return nil, err
+	}
+
+	if update.FallbackTeams != nil {
+		err = replaceFallbackTeams(ctx, tx, teamName, update.FallbackTeams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fallbackTeams, err := getFallbackTeams(ctx, tx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.FallbackTeams = fallbackTeams
+	return &settings, nil
+}
+
+func getFallbackTeams(ctx context.Context, q sqlx.QueryerContext, teamName string) ([]string, error) {
+	getQuery := `
+		SELECT fallback_team_name
+		FROM team_fallback_teams
+		WHERE team_name = $1
+		ORDER BY priority
+	`
+
+	fallbackTeams := make([]string, 0)
+	if err := sqlx.SelectContext(ctx, q, &fallbackTeams, getQuery, teamName); err != nil {
+		return nil, err
+	}
+	return fallbackTeams, nil
+}
+
+func replaceFallbackTeams(ctx context.Context, tx execer, teamName string, fallbackTeams []string) error {
+	deleteQuery := `DELETE FROM team_fallback_teams WHERE team_name = $1`
+	if _, err := tx.ExecContext(ctx, deleteQuery, teamName); err != nil {
+		return err
+	}
+
+	insertQuery := `
+		INSERT INTO team_fallback_teams (team_name, fallback_team_name, priority)
+		VALUES ($1, $2, $3)
+	`
+	for i, fallbackTeam := range fallbackTeams {
+		if _, err := tx.ExecContext(ctx, insertQuery, teamName, fallbackTeam, i); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return fmt.Errorf("fallback team %s is not exist: %w", fallbackTeam, domain.ErrNotFound())
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// DeactivateMembers deactivates the given members of the team and applies the
+// planned reassignments of their open reviews in one transaction. It returns
+// the deactivated users and the reassignments that were applied.
+func (t *TeamRepository) DeactivateMembers(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	reassignments []domain.Reassignment,
+) ([]domain.User, []domain.Reassignment, error) {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	lockQuery := `
+		SELECT user_id
+		FROM users
+		WHERE user_id = ANY($1) AND is_active
+		ORDER BY user_id
+		FOR UPDATE
+	`
+
+	var activeIDs []string
+	err = tx.SelectContext(ctx, &activeIDs, lockQuery, pq.Array(userIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deactivateQuery := `
+		UPDATE users u
+		SET is_active = false, updated_at = NOW()
+		FROM team_members tm
+		WHERE tm.user_id = u.user_id
+			AND tm.team_name = $1
+			AND u.user_id = ANY($2)
+		RETURNING u.user_id, u.username, u.is_active
+	`
+
+	users := make([]domain.User, 0, len(userIDs))
+	err = tx.SelectContext(ctx, &users, deactivateQuery, teamName, pq.Array(userIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(users) != len(userIDs) {
+		err = fmt.Errorf("some users are not members of team %s: %w", teamName, domain.ErrNotFound())
+		return nil, nil, err
+	}
+
+	// история пишется до переназначения, пока уходящие ещё числятся ревьюверами
+	err = recordActivityChanged(ctx, tx, activeIDs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	applied, err := applyReassignments(ctx, tx, reassignments)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, applied, nil
+}
+
+// appliedReassignment is a row changed by applyReassignments with its position
+// in the plan.
+type appliedReassignment struct {
+	N int64 `db:"n"`
+	domain.Reassignment
+}
+
+// applyReassignments replaces reviewers of many pull requests with a single
+// statement, records a REASSIGNED event and enqueues pr.reassigned webhooks
+// for each of them. The plan is made outside the transaction, so the pull
+// requests are locked first and only reviewers of still open ones are
+// replaced; the returned reassignments are the ones actually applied.
+func applyReassignments(ctx context.Context, tx *sqlx.Tx, reassignments []domain.Reassignment) ([]domain.Reassignment, error) {
+	applied := make([]domain.Reassignment, 0, len(reassignments))
+	if len(reassignments) == 0 {
+		return applied, nil
+	}
+
+	prIDs := make([]string, 0, len(reassignments))
+	oldIDs := make([]string, 0, len(reassignments))
+	newIDs := make([]string, 0, len(reassignments))
+	teamNames := make([]string, 0, len(reassignments))
+	for _, r := range reassignments {
+		prIDs = append(prIDs, r.PullRequestID)
+		oldIDs = append(oldIDs, r.OldReviewerID)
+		newIDs = append(newIDs, r.NewReviewerID)
+		teamNames = append(teamNames, r.TeamName)
+	}
+
+	// a merge or close of a planned PR waits for this transaction, or this
+	// one sees its new status
+	lockQuery := `
+		SELECT pull_request_id
+		FROM pull_requests
+		WHERE pull_request_id = ANY($1)
+		ORDER BY pull_request_id
+		FOR UPDATE
+	`
+
+	var locked []string
+	if err := tx.SelectContext(ctx, &locked, lockQuery, pq.Array(prIDs)); err != nil {
+		return nil, err
+	}
+
+	reassignQuery := `
+		UPDATE pull_request_reviewers prr
+		SET user_id = r.new_reviewer_id,
+			team_name = r.team_name,
+			assigned_at = NOW(),
+			decision = NULL,
+			decided_at = NULL
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[]) WITH ORDINALITY
+				AS r(pull_request_id, old_reviewer_id, new_reviewer_id, team_name, n),
+			pull_requests pr
+		WHERE prr.pull_request_id = r.pull_request_id
+			AND prr.user_id = r.old_reviewer_id
+			AND pr.pull_request_id = prr.pull_request_id
+			AND pr.status = 'OPEN'
+		RETURNING r.n, r.pull_request_id, r.old_reviewer_id, r.new_reviewer_id, r.team_name
+	`
+
+	var rows []appliedReassignment
+	err := tx.SelectContext(ctx, &rows, reassignQuery,
+		pq.Array(prIDs), pq.Array(oldIDs), pq.Array(newIDs), pq.Array(teamNames),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return applied, nil
+	}
+
+	// RETURNING gives no order; keep the one of the plan
+	slices.SortFunc(rows, func(a, b appliedReassignment) int {
+		return cmp.Compare(a.N, b.N)
+	})
+
+	prIDs, oldIDs, newIDs = prIDs[:0], oldIDs[:0], newIDs[:0]
+	for _, row := range rows {
+		applied = append(applied, row.Reassignment)
+		prIDs = append(prIDs, row.PullRequestID)
+		oldIDs = append(oldIDs, row.OldReviewerID)
+		newIDs = append(newIDs, row.NewReviewerID)
+	}
+
+	recordQuery := `
+		INSERT INTO pull_request_events (pull_request_id, event_type, actor_id, reviewer_id, old_reviewer_id)
+		SELECT r.pull_request_id, 'REASSIGNED', NULLIF($4, ''), r.new_reviewer_id, r.old_reviewer_id
+		FROM unnest($1::text[], $2::text[], $3::text[]) WITH ORDINALITY
+			AS r(pull_request_id, old_reviewer_id, new_reviewer_id, n)
+		ORDER BY r.n
+	`
+
+	_, err = tx.ExecContext(ctx, recordQuery,
+		pq.Array(prIDs), pq.Array(oldIDs), pq.Array(newIDs), domain.ActorFromContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range applied {
+		err = enqueueWebhooks(ctx, tx, r.PullRequestID, domain.WebhookEventPRReassigned, map[string]any{
+			"old_reviewer_id": r.OldReviewerID,
+			"new_reviewer_id": r.NewReviewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return applied, nil
+}
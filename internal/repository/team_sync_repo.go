@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SyncTeam makes the members of the team exactly match members: new users are
+// added, changed usernames and activity are updated and everyone else is
+// removed from the team. A missing team is created with the default settings.
+// With dryRun the diff is computed under the same locks but nothing is changed.
+func (t *TeamRepository) SyncTeam(
+	ctx context.Context,
+	teamName string,
+	members []domain.User,
+	dryRun bool,
+) (*domain.TeamSyncDiff, error) {
+	tx, err := t.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	lockTeamQuery := `SELECT team_name FROM teams WHERE team_name = $1 FOR UPDATE`
+
+	var lockedTeam string
+	teamCreated := false
+	err = tx.GetContext(ctx, &lockedTeam, lockTeamQuery, teamName)
+	if err == sql.ErrNoRows {
+		teamCreated = true
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if teamCreated && !dryRun {
+		createTeamQuery := `
+			INSERT INTO teams (team_name, reviewer_strategy, required_reviewers) VALUES ($1, $2, $3)`
+		_, err = tx.ExecContext(ctx, createTeamQuery,
+			teamName, domain.DefaultReviewerStrategy, domain.DefaultRequiredReviewers,
+		)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				err = domain.ErrTeamExists()
+			}
+			return nil, err
+		}
+	}
+
+	currentQuery := `
+		SELECT u.user_id, u.username, u.is_active
+		FROM team_members tm
+		JOIN users u ON u.user_id = tm.user_id
+		WHERE tm.team_name = $1
+		ORDER BY u.user_id
+	`
+
+	current := make([]domain.User, 0)
+	err = tx.SelectContext(ctx, &current, currentQuery, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := domain.DiffTeamMembers(teamName, current, members)
+	diff.DryRun = dryRun
+	diff.TeamCreated = teamCreated
+	if dryRun {
+		return diff, nil
+	}
+
+	err = upsertUsers(ctx, tx, members)
+	if err != nil {
+		return nil, err
+	}
+
+	addMemberQuery := `
+		INSERT INTO team_members (team_name, user_id, joined_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, team_name) DO NOTHING
+	`
+
+	for _, user := range diff.Added {
+		_, err = tx.ExecContext(ctx, addMemberQuery, teamName, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		removedIDs := make([]string, 0, len(diff.Removed))
+		for _, user := range diff.Removed {
+			removedIDs = append(removedIDs, user.UserID)
+		}
+
+		removeQuery := `DELETE FROM team_members WHERE team_name = $1 AND user_id = ANY($2)`
+		_, err = tx.ExecContext(ctx, removeQuery, teamName, pq.Array(removedIDs))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+// upsertUsers creates the users that don't exist yet and updates username and
+// activity of the others. An ACTIVITY_CHANGED event is recorded for every
+// user whose activity actually changed.
+func upsertUsers(ctx context.Context, tx *sqlx.Tx, users []domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+	}
+
+	lockQuery := `
+		SELECT user_id, username, is_active
+		FROM users
+		WHERE user_id = ANY($1)
+		ORDER BY user_id
+		FOR UPDATE
+	`
+
+	var existing []domain.User
+	if err := tx.SelectContext(ctx, &existing, lockQuery, pq.Array(userIDs)); err != nil {
+		return err
+	}
+
+	wasActive := make(map[string]bool, len(existing))
+	for _, user := range existing {
+		wasActive[user.UserID] = user.IsActive
+	}
+
+	upsertQuery := `
+		INSERT INTO users (user_id, username, is_active)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username,
+			is_active = EXCLUDED.is_active,
+			updated_at = NOW()
+		WHERE users.username <> EXCLUDED.username
+			OR users.is_active <> EXCLUDED.is_active
+	`
+
+	var activated, deactivated []string
+	for _, user := range users {
+		if _, err := tx.ExecContext(ctx, upsertQuery, user.UserID, user.Username, user.IsActive); err != nil {
+			return err
+		}
+
+		active, ok := wasActive[user.UserID]
+		if !ok || active == user.IsActive {
+			continue
+		}
+		wasActive[user.UserID] = user.IsActive
+
+		if user.IsActive {
+			activated = append(activated, user.UserID)
+		} else {
+			deactivated = append(deactivated, user.UserID)
+		}
+	}
+
+	if err := recordActivityChanged(ctx, tx, activated, true); err != nil {
+		return err
+	}
+	return recordActivityChanged(ctx, tx, deactivated, false)
+}
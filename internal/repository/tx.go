@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// execer is implemented by *sql.Tx and *sqlx.Tx, so helpers can run inside
+// whichever transaction the caller has opened.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
@@ -19,7 +19,34 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 	}
 }
 
-func (u *UserRepository) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, string, error) {
+func (u *UserRepository) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, []string, error) {
+	tx, err := u.db.BeginTxx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			tx.Commit()
+		}
+	}()
+
+	lockQuery := `
+		SELECT is_active
+		FROM users
+		WHERE user_id = $1
+		FOR UPDATE
+	`
+
+	var wasActive bool
+	if err = tx.GetContext(ctx, &wasActive, lockQuery, userID); err != nil {
+		if err == sql.ErrNoRows {
+			err = domain.ErrNotFound()
+		}
+		return nil, nil, err
+	}
+
 	updateQuery := `
 		UPDATE users
 		SET is_active = $2, updated_at = NOW()
@@ -28,31 +55,22 @@ func (u *UserRepository) SetIsActive(ctx context.Context, userID string, isActiv
 	`
 
 	var user domain.User
-	if err := u.db.QueryRowContext(ctx, updateQuery, userID, isActive).
+	if err = tx.QueryRowContext(ctx, updateQuery, userID, isActive).
 		Scan(&user.UserID, &user.Username, &user.IsActive); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, "", domain.ErrNotFound()
-		}
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	getTeamQuery := `
-		SELECT team_name
-		FROM team_members
-		WHERE user_id = $1
-		ORDER BY team_name
-		LIMIT 1
-	`
-
-	var teamName string
-	if err := u.db.GetContext(ctx, &teamName, getTeamQuery, userID); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, "", domain.ErrNotFound()
+	if wasActive != isActive {
+		if err = recordActivityChanged(ctx, tx, []string{userID}, isActive); err != nil {
+			return nil, nil, err
 		}
-		return nil, "", err
 	}
 
-	return &user, teamName, nil
+	teamNames, err := getTeamNames(ctx, tx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &user, teamNames, nil
 }
 
 func (u *UserRepository) GetUser(ctx context.Context, userID string) (*domain.User, error) {
@@ -65,26 +83,29 @@ func (u *UserRepository) GetUser(ctx context.Context, userID string) (*domain.Us
 	var user domain.User
 	if err := u.db.GetContext(ctx, &user, getUserQuery, userID); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user is not exist %w", domain.ErrNotFound())
+			return nil, fmt.Errorf("user is not exist: %w", domain.ErrNotFound())
 		}
+		return nil, err
 	}
 	return &user, nil
 }
 
-func (u *UserRepository) GetTeamName(ctx context.Context, userID string) (string, error) {
-	getTeamNameQuery := `
-		SELECT team_name 
+// GetTeamNames returns all teams the user is a member of, ordered by name.
+func (u *UserRepository) GetTeamNames(ctx context.Context, userID string) ([]string, error) {
+	return getTeamNames(ctx, u.db, userID)
+}
+
+func getTeamNames(ctx context.Context, q sqlx.QueryerContext, userID string) ([]string, error) {
+	getTeamNamesQuery := `
+		SELECT team_name
 		FROM team_members
 		WHERE user_id = $1
 		ORDER BY team_name
-		LIMIT 1
 	`
 
-	var teamName string
-	if err := u.db.GetContext(ctx, &teamName, getTeamNameQuery, userID); err != nil {
-		if err == sql.ErrNoRows {
-			return "", fmt.Errorf("user is not exist %w", domain.ErrNotFound())
-		}
+	teamNames := make([]string, 0)
+	if err := sqlx.SelectContext(ctx, q, &teamNames, getTeamNamesQuery, userID); err != nil {
+		return nil, err
 	}
-	return teamName, nil
+	return teamNames, nil
 }
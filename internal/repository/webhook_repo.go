@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (wr *WebhookRepository) Create(ctx context.Context, teamName, url, secret string) (*domain.Webhook, error) {
+	createQuery := `
+		INSERT INTO webhooks (team_name, url, secret)
+		VALUES ($1, $2, $3)
+		RETURNING webhook_id, team_name, url, secret, created_at
+	`
+
+	var webhook domain.Webhook
+	if err := wr.db.GetContext(ctx, &webhook, createQuery, teamName, url, secret); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return nil, domain.ErrWebhookExists()
+			case "23503":
+				return nil, fmt.Errorf("team %s is not exist: %w", teamName, domain.ErrNotFound())
+			}
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (wr *WebhookRepository) List(ctx context.Context, teamName string) ([]domain.Webhook, error) {
+	listQuery := `
+		SELECT webhook_id, team_name, url, created_at
+		FROM webhooks
+		WHERE team_name = $1
+		ORDER BY webhook_id
+	`
+
+	webhooks := make([]domain.Webhook, 0)
+	if err := wr.db.SelectContext(ctx, &webhooks, listQuery, teamName); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (wr *WebhookRepository) Delete(ctx context.Context, webhookID int64) error {
+	deleteQuery := `DELETE FROM webhooks WHERE webhook_id = $1`
+
+	res, err := wr.db.ExecContext(ctx, deleteQuery, webhookID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return fmt.Errorf("webhook %d is not exist: %w", webhookID, domain.ErrNotFound())
+	}
+	return nil
+}
+
+// ClaimDueDeliveries takes up to limit pending deliveries whose time has come and
+// postpones them by lease, so that other dispatchers skip them while they are
+// being sent. A delivery that is not marked before the lease expires is retried.
+func (wr *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	claimQuery := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.webhook_id = d.webhook_id
+			AND d.delivery_id IN (
+				SELECT delivery_id
+				FROM webhook_deliveries
+				WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at, delivery_id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+		RETURNING d.delivery_id, d.webhook_id, w.url, w.secret, d.event_type, d.payload, d.attempts
+	`
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	if err := wr.db.SelectContext(ctx, &deliveries, claimQuery, limit, lease.Seconds()); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (wr *WebhookRepository) MarkDelivered(ctx context.Context, deliveryID int64) error {
+	updateQuery := `
+		UPDATE webhook_deliveries
+		SET status = 'DELIVERED',
+			attempts = attempts + 1,
+			last_error = NULL,
+			delivered_at = NOW()
+		WHERE delivery_id = $1
+	`
+
+	_, err := wr.db.ExecContext(ctx, updateQuery, deliveryID)
+	return err
+}
+
+func (wr *WebhookRepository) MarkRetry(ctx context.Context, deliveryID int64, lastErr string, nextAttemptAt time.Time) error {
+	updateQuery := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			last_error = $2,
+			next_attempt_at = $3
+		WHERE delivery_id = $1
+	`
+
+	_, err := wr.db.ExecContext(ctx, updateQuery, deliveryID, lastErr, nextAttemptAt)
+	return err
+}
+
+func (wr *WebhookRepository) MarkDead(ctx context.Context, deliveryID int64, lastErr string) error {
+	updateQuery := `
+		UPDATE webhook_deliveries
+		SET status = 'DEAD',
+			attempts = attempts + 1,
+			last_error = $2
+		WHERE delivery_id = $1
+	`
+
+	_, err := wr.db.ExecContext(ctx, updateQuery, deliveryID, lastErr)
+	return err
+}
+
+// enqueueWebhooks puts the event into the outbox for every webhook of the team
+// the PR was created in. It runs in the caller's transaction, so nothing is
+// sent for a change that is rolled back. The payload carries the state of the
+// PR as seen by that transaction, extended with the fields of extra.
+func enqueueWebhooks(ctx context.Context, tx execer, prID string, event domain.WebhookEvent, extra map[string]any) error {
+	if extra == nil {
+		extra = map[string]any{}
+	}
+
+	extraJSON, err := json.Marshal(extra)
+	if err != nil {
+		return err
+	}
+
+	enqueueQuery := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT w.webhook_id, $2, jsonb_build_object(
+				'event', $2::text,
+				'occurred_at', NOW(),
+				'pull_request', jsonb_build_object(
+					'pull_request_id', pr.pull_request_id,
+					'pull_request_name', pr.pull_request_name,
+					'author_id', pr.author_id,
+					'team_name', pr.team_name,
+					'status', pr.status,
+					'assigned_reviewers', COALESCE((
+						SELECT jsonb_agg(prr.user_id ORDER BY prr.assigned_at, prr.user_id)
+						FROM pull_request_reviewers prr
+						WHERE prr.pull_request_id = pr.pull_request_id
+					), '[]'::jsonb),
+					'created_at', pr.created_at,
+					'merged_at', pr.merged_at
+				)
+			) || $3::jsonb
+		FROM pull_requests pr
+		JOIN webhooks w ON w.team_name = pr.team_name
+		WHERE pr.pull_request_id = $1
+		ORDER BY w.webhook_id
+	`
+
+	_, err = tx.ExecContext(ctx, enqueueQuery, prID, string(event), string(extraJSON))
+	return err
+}
@@ -0,0 +1,47 @@
+// Package requestid assigns ids to the requests served by the HTTP and gRPC
+// transports, so that both treat incoming ids the same way.
+package requestid
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/theartofdevel/logging"
+)
+
+const (
+	// Header carries the id in HTTP requests and responses; gRPC uses its
+	// lower-case form as the metadata key.
+	Header = "X-Request-ID"
+	// MaxLength limits incoming ids; longer ones are replaced.
+	MaxLength = 128
+)
+
+// Resolve returns the incoming id if it is usable, or a new one.
+func Resolve(incoming string) string {
+	if incoming == "" || len(incoming) > MaxLength {
+		return New()
+	}
+	return incoming
+}
+
+// New generates a random id.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Start resolves the id of a request and returns it with a context carrying
+// it and a logger derived from logger that adds it to every line; the logger
+// is put into the context too.
+func Start(ctx context.Context, logger *logging.Logger, incoming string) (context.Context, *logging.Logger, string) {
+	requestID := Resolve(incoming)
+	requestLogger := logger.With(logging.StringAttr("request_id", requestID))
+
+	ctx = domain.ContextWithRequestID(ctx, requestID)
+	ctx = logging.ContextWithLogger(ctx, requestLogger)
+	return ctx, requestLogger, requestID
+}
@@ -0,0 +1,40 @@
+package requestid_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/requestid"
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/theartofdevel/logging"
+)
+
+func TestResolve(t *testing.T) {
+	assert.Equal(t, "req-42", requestid.Resolve("req-42"))
+
+	maxLength := strings.Repeat("a", requestid.MaxLength)
+	assert.Equal(t, maxLength, requestid.Resolve(maxLength))
+
+	for _, incoming := range []string{"", maxLength + "a"} {
+		generated := requestid.Resolve(incoming)
+		assert.Len(t, generated, 32)
+		assert.NotEqual(t, generated, requestid.Resolve(incoming))
+	}
+}
+
+func TestStart(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ctx, requestLogger, requestID := requestid.Start(context.Background(), logger, "req-42")
+	assert.Equal(t, "req-42", requestID)
+	assert.Equal(t, "req-42", domain.RequestIDFromContext(ctx))
+	assert.Same(t, requestLogger, logging.L(ctx))
+
+	requestLogger.Info("served")
+	assert.Contains(t, buf.String(), `"request_id":"req-42"`)
+}
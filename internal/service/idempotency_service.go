@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/theartofdevel/logging"
+)
+
+// DefaultIdempotencyKeyTTL is how long idempotency keys are kept unless
+// WithIdempotencyKeyTTL sets otherwise.
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// PurgeIdempotencyKeys removes the idempotency keys older than the TTL, so
+// that they can be used again, and returns how many were removed.
+func (s *Service) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	purged, err := s.prs.DeleteIdempotencyRecords(ctx, time.Now().Add(-s.idempotencyKeyTTL))
+	if err != nil {
+		s.log(ctx).Error("failed to purge idempotency keys", logging.ErrAttr(err))
+		return 0, err
+	}
+
+	if purged > 0 {
+		s.log(ctx).Info("idempotency keys were purged", logging.IntAttr("count", int(purged)))
+	}
+	return purged, nil
+}
+
+// RunIdempotencyKeyCleanup purges expired idempotency keys every interval
+// until ctx is cancelled.
+func (s *Service) RunIdempotencyKeyCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// ошибка уже записана в лог, следующая попытка будет через interval
+		_, _ = s.PurgeIdempotencyKeys(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,25 +3,60 @@ package service
 import (
 	"ReilBleem13/pull_requests_service/internal/domain"
 	"context"
+	"time"
 )
 
 type TeamRepositoryInterface interface {
-	Create(ctx context.Context, teamName string, users []domain.User) error
+	Create(ctx context.Context, teamName string, users []domain.User, settings domain.TeamSettings) error
 	Get(ctx context.Context, teamName string) ([]domain.User, error)
+	GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error)
+	UpdateSettings(ctx context.Context, teamName string, update domain.TeamSettingsUpdate) (*domain.TeamSettings, error)
+	DeactivateMembers(ctx context.Context, teamName string, userIDs []string, reassignments []domain.Reassignment) ([]domain.User, []domain.Reassignment, error)
+	AddMembers(ctx context.Context, teamName string, users []domain.User) error
+	RemoveMembers(ctx context.Context, teamName string, userIDs []string, reassignments []domain.Reassignment) ([]domain.Reassignment, error)
+	MoveMember(ctx context.Context, userID, fromTeam, toTeam string) error
+	SyncTeam(ctx context.Context, teamName string, members []domain.User, dryRun bool) (*domain.TeamSyncDiff, error)
 }
 type UserRepositoryInterface interface {
-	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, string, error)
+	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, []string, error)
 	GetUser(ctx context.Context, userID string) (*domain.User, error)
-	GetTeamName(ctx context.Context, userID string) (string, error)
+	GetTeamNames(ctx context.Context, userID string) ([]string, error)
 }
 
 type PullRequestRepositoryInterface interface {
 	GetActiveTeamMembers(ctx context.Context, teamName, authorID string) ([]domain.User, error)
+	CountOpenAssignments(ctx context.Context, userIDs []string) (map[string]int, error)
 	GetPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error)
+	GetHistory(ctx context.Context, prID string) ([]domain.PullRequestEvent, error)
 	GetPullRequestByID(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
-	Create(ctx context.Context, prID, prName, authorID string, assignedUsers []string) error
-	Merge(ctx context.Context, prID string) error
-	ReAssign(ctx context.Context, prID, oldReviewerID, newReviewerID string) error
+	GetOpenPullRequestsByReviewers(ctx context.Context, userIDs []string) ([]domain.PullRequest, error)
+	List(ctx context.Context, filter domain.PullRequestFilter) ([]domain.PullRequest, error)
+	Create(ctx context.Context, prID, prName, authorID, teamName string, status domain.PRStatus, reviewers []domain.Reviewer, idempotency *domain.IdempotencyRecord) error
+	GetIdempotencyRecord(ctx context.Context, actorID, key string) (*domain.IdempotencyRecord, error)
+	DeleteIdempotencyRecords(ctx context.Context, createdBefore time.Time) (int64, error)
+	Merge(ctx context.Context, prID string) (*domain.PullRequest, bool, error)
+	ReAssign(ctx context.Context, prID, oldReviewerID string, newReviewer domain.Reviewer) error
+	SubmitReview(ctx context.Context, prID, reviewerID string, decision domain.ReviewDecision) (*domain.PullRequest, error)
+	ChangeStatus(ctx context.Context, prID string, from, to domain.PRStatus, reviewers []domain.Reviewer) (*domain.PullRequest, error)
+
+	GetReviewerStats(ctx context.Context) ([]domain.ReviewerStats, error)
+	GetAuthorStats(ctx context.Context) ([]domain.AuthorStats, error)
+	GetTeamStats(ctx context.Context) ([]domain.TeamStats, error)
+	GetAverageMergeTime(ctx context.Context) (float64, error)
+}
+
+type WebhookRepositoryInterface interface {
+	Create(ctx context.Context, teamName, url, secret string) (*domain.Webhook, error)
+	List(ctx context.Context, teamName string) ([]domain.Webhook, error)
+	Delete(ctx context.Context, webhookID int64) error
+}
+
+// MetricsRecorder counts business events of the service.
+type MetricsRecorder interface {
+	PullRequestCreated()
+	PullRequestMerged()
+	ReviewersReassigned(count int)
+	NoCandidate(count int)
 }
 
 type LoggerInterfaces interface {
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/theartofdevel/logging"
+)
+
+func (s *Service) ListPullRequests(ctx context.Context, filter domain.PullRequestFilter, cursor string) (*domain.PullRequestPage, error) {
+	s.log(ctx).Info("attempt to list pull requests",
+		logging.StringAttr("status", filter.Status.String()),
+		logging.StringAttr("authorID", filter.AuthorID),
+		logging.StringAttr("reviewerID", filter.ReviewerID),
+		logging.StringAttr("teamName", filter.TeamName),
+		logging.IntAttr("limit", filter.Limit),
+	)
+
+	if filter.Limit == 0 {
+		filter.Limit = domain.DefaultPullRequestPageSize
+	}
+
+	var v domain.Validation
+	v.Check(filter.Status == "" || filter.Status.IsValid(), "status", domain.RuleEnum, "status is invalid")
+	v.Check(filter.Limit > 0 && filter.Limit <= domain.MaxPullRequestPageSize, "limit", domain.RuleRange, "limit is out of range")
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			s.log(ctx).Error("failed to list pull requests, invalid cursor",
+				logging.ErrAttr(err),
+			)
+			v.Add("cursor", domain.RuleFormat, "cursor is invalid")
+		}
+		filter.After = after
+	}
+
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to list pull requests",
+			logging.StringAttr("status", filter.Status.String()),
+			logging.IntAttr("limit", filter.Limit),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	// one extra row tells whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+
+	pullRequests, err := s.prs.List(ctx, filter)
+	if err != nil {
+		s.log(ctx).Error("failed to list pull requests",
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	page := &domain.PullRequestPage{PullRequests: pullRequests}
+	if len(pullRequests) > limit {
+		page.PullRequests = pullRequests[:limit]
+
+		last := page.PullRequests[limit-1]
+		page.NextCursor = encodeCursor(domain.PullRequestCursor{
+			CreatedAt:     *last.CreatedAt,
+			PullRequestID: last.PullRequestID,
+		})
+	}
+
+	s.log(ctx).Info("pull requests was successfully listed",
+		logging.IntAttr("count pr", len(page.PullRequests)),
+		logging.BoolAttr("has next page", page.NextCursor != ""),
+	)
+	return page, nil
+}
+
+func encodeCursor(cursor domain.PullRequestCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor string) (*domain.PullRequestCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded domain.PullRequestCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, err
+	}
+
+	if decoded.PullRequestID == "" || decoded.CreatedAt.IsZero() {
+		return nil, errors.New("cursor is incomplete")
+	}
+	return &decoded, nil
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/repository"
+	"ReilBleem13/pull_requests_service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListPullRequests_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend'), ('frontend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('alice', 'Alice', true),
+		('bob', 'Bob', true),
+		('carol', 'Carol', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'alice'),
+		('backend', 'bob'),
+		('frontend', 'carol');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status, created_at, merged_at) VALUES
+		('pr-1', 'One',   'alice', 'backend',  'MERGED', '2025-01-01T10:00:00Z', '2025-01-02T10:00:00Z'),
+		('pr-2', 'Two',   'alice', 'backend',  'OPEN',   '2025-01-02T10:00:00Z', NULL),
+		('pr-3', 'Three', 'bob',   'backend',  'OPEN',   '2025-01-03T10:00:00Z', NULL),
+		('pr-4', 'Four',  'carol', 'frontend', 'OPEN',   '2025-01-04T10:00:00Z', NULL),
+		('pr-5', 'Five',  'carol', 'frontend', 'MERGED', '2025-01-04T10:00:00Z', '2025-01-05T10:00:00Z');
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name) VALUES
+		('pr-1', 'bob', 'backend'),
+		('pr-2', 'bob', 'backend'),
+		('pr-3', 'alice', 'backend'),
+		('pr-4', 'bob', 'backend'),
+		('pr-4', 'alice', 'backend');
+	`)
+	require.NoError(t, err)
+
+	listIDs := func(page *domain.PullRequestPage) []string {
+		ids := make([]string, 0, len(page.PullRequests))
+		for _, pr := range page.PullRequests {
+			ids = append(ids, pr.PullRequestID)
+		}
+		return ids
+	}
+
+	t.Run("walk all pages in created_at order", func(t *testing.T) {
+		var (
+			ids    []string
+			cursor string
+			pages  int
+		)
+		for {
+			page, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{Limit: 2}, cursor)
+			require.NoError(t, err)
+			ids = append(ids, listIDs(page)...)
+			pages++
+
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		assert.Equal(t, []string{"pr-1", "pr-2", "pr-3", "pr-4", "pr-5"}, ids)
+		assert.Equal(t, 3, pages)
+	})
+
+	t.Run("load reviewers and timestamps", func(t *testing.T) {
+		page, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{AuthorID: "carol"}, "")
+		require.NoError(t, err)
+
+		require.Len(t, page.PullRequests, 2)
+		assert.ElementsMatch(t, []string{"alice", "bob"}, page.PullRequests[0].AssignedReviewers)
+		assert.NotNil(t, page.PullRequests[0].CreatedAt)
+		assert.Empty(t, page.PullRequests[1].AssignedReviewers)
+		assert.NotNil(t, page.PullRequests[1].MergedAt)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("filter by status, reviewer and team", func(t *testing.T) {
+		page, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{
+			Status:     domain.PRStatusOpen,
+			ReviewerID: "bob",
+		}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr-2", "pr-4"}, listIDs(page))
+
+		page, err = svc.ListPullRequests(ctx, domain.PullRequestFilter{TeamName: "backend"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr-1", "pr-2", "pr-3"}, listIDs(page))
+	})
+
+	t.Run("filter by created and merged time range", func(t *testing.T) {
+		from := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC)
+
+		page, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{CreatedFrom: &from, CreatedTo: &to}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr-2", "pr-3"}, listIDs(page))
+
+		mergedFrom := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+		page, err = svc.ListPullRequests(ctx, domain.PullRequestFilter{MergedFrom: &mergedFrom}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr-5"}, listIDs(page))
+	})
+
+	t.Run("fail on invalid cursor", func(t *testing.T) {
+		_, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{}, "not-a-cursor")
+		assertAppError(t, err, domain.CodeInvalidRequest, "cursor is invalid")
+	})
+
+	t.Run("fail on invalid status and limit", func(t *testing.T) {
+		_, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{Status: "DELETED"}, "")
+		assertAppError(t, err, domain.CodeInvalidRequest, "status is invalid")
+
+		_, err = svc.ListPullRequests(ctx, domain.PullRequestFilter{Limit: 1000}, "")
+		assertAppError(t, err, domain.CodeInvalidRequest, "limit is out of range")
+	})
+
+	t.Run("filter by the team the PR was created in", func(t *testing.T) {
+		_, err := db.Exec(`UPDATE team_members SET team_name = 'frontend' WHERE user_id = 'alice'`)
+		require.NoError(t, err)
+
+		page, err := svc.ListPullRequests(ctx, domain.PullRequestFilter{TeamName: "backend"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr-1", "pr-2", "pr-3"}, listIDs(page))
+		assert.Equal(t, "backend", page.PullRequests[0].TeamName)
+	})
+}
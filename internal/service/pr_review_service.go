@@ -0,0 +1,62 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+
+	"github.com/theartofdevel/logging"
+)
+
+// SubmitReview records the decision of an assigned reviewer. A later decision
+// replaces the earlier one; reassignment clears it for the new reviewer. An
+// authenticated caller may only submit their own review unless they are an
+// admin.
+func (s *Service) SubmitReview(
+	ctx context.Context,
+	prID, reviewerID string,
+	decision domain.ReviewDecision,
+) (*domain.PullRequest, error) {
+	s.log(ctx).Info("attempt to submit review",
+		logging.StringAttr("prID", prID),
+		logging.StringAttr("reviewerID", reviewerID),
+		logging.StringAttr("decision", string(decision)),
+	)
+
+	var v domain.Validation
+	v.Required("pull_request_id", prID)
+	v.Required("reviewer_id", reviewerID)
+	v.Check(decision.IsValid(), "decision", domain.RuleEnum, "decision is invalid")
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to submit review",
+			logging.StringAttr("prID", prID),
+			logging.StringAttr("reviewerID", reviewerID),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	if actorID := domain.ActorFromContext(ctx); actorID != "" && actorID != reviewerID && !domain.ActorIsAdmin(ctx) {
+		s.log(ctx).Error("failed to submit review, caller is not the reviewer",
+			logging.StringAttr("prID", prID),
+			logging.StringAttr("reviewerID", reviewerID),
+		)
+		return nil, domain.ErrReviewAsOtherUser()
+	}
+
+	pullRequest, err := s.prs.SubmitReview(ctx, prID, reviewerID, decision)
+	if err != nil {
+		s.log(ctx).Error("failed to submit review",
+			logging.StringAttr("prID", prID),
+			logging.StringAttr("reviewerID", reviewerID),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.log(ctx).Info("review was successfully submitted",
+		logging.StringAttr("prID", prID),
+		logging.StringAttr("reviewerID", reviewerID),
+		logging.StringAttr("decision", string(decision)),
+	)
+	return pullRequest, nil
+}
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/repository"
+	"ReilBleem13/pull_requests_service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SubmitReview_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name, required_approvals) VALUES ('backend', 2), ('relaxed', 0);
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'Author', true),
+		('rev-1', 'R1', true),
+		('rev-2', 'R2', true),
+		('spare', 'Spare', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2'),
+		('backend', 'spare'),
+		('relaxed', 'author');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status) VALUES
+		('pr-1', 'One', 'author', 'backend', 'OPEN'),
+		('pr-relaxed', 'Relaxed', 'author', 'relaxed', 'OPEN');
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name) VALUES
+		('pr-1', 'rev-1', 'backend'),
+		('pr-1', 'rev-2', 'backend');
+	`)
+	require.NoError(t, err)
+
+	decisionOf := func(t *testing.T, pr *domain.PullRequest, userID string) *domain.ReviewDecision {
+		for _, reviewer := range pr.Reviewers {
+			if reviewer.UserID == userID {
+				return reviewer.Decision
+			}
+		}
+		t.Fatalf("reviewer %s is not assigned", userID)
+		return nil
+	}
+
+	t.Run("validate request", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr-1", "rev-1", "LGTM")
+		assertAppError(t, err, domain.CodeInvalidRequest, "decision is invalid")
+
+		_, err = svc.SubmitReview(ctx, "pr-1", "spare", domain.ReviewDecisionApproved)
+		assertAppError(t, err, domain.CodeNotAssigned)
+
+		_, err = svc.SubmitReview(ctx, "ghost", "rev-1", domain.ReviewDecisionApproved)
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("refuse merge without required approvals", func(t *testing.T) {
+		pr, err := svc.SubmitReview(ctx, "pr-1", "rev-1", domain.ReviewDecisionApproved)
+		require.NoError(t, err)
+		require.NotNil(t, decisionOf(t, pr, "rev-1"))
+		assert.Equal(t, domain.ReviewDecisionApproved, *decisionOf(t, pr, "rev-1"))
+		assert.Nil(t, decisionOf(t, pr, "rev-2"))
+
+		_, err = svc.MergePullRequest(ctx, "pr-1")
+		assertAppError(t, err, domain.CodeNotApproved, "1 of 2")
+
+		_, err = svc.SubmitReview(ctx, "pr-1", "rev-2", domain.ReviewDecisionChangesRequested)
+		require.NoError(t, err)
+
+		_, err = svc.MergePullRequest(ctx, "pr-1")
+		assertAppError(t, err, domain.CodeNotApproved, "requested changes")
+	})
+
+	t.Run("reset decision on reassign", func(t *testing.T) {
+		pr, newReviewerID, err := svc.ReAssign(ctx, "pr-1", "rev-2")
+		require.NoError(t, err)
+		assert.Equal(t, "spare", newReviewerID)
+		assert.Nil(t, decisionOf(t, pr, "spare"))
+		assert.NotNil(t, decisionOf(t, pr, "rev-1"))
+	})
+
+	t.Run("merge once approvals are met", func(t *testing.T) {
+		_, err := svc.SubmitReview(ctx, "pr-1", "spare", domain.ReviewDecisionApproved)
+		require.NoError(t, err)
+
+		pr, err := svc.MergePullRequest(ctx, "pr-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusMerged, pr.Status)
+
+		_, err = svc.SubmitReview(ctx, "pr-1", "spare", domain.ReviewDecisionCommented)
+		assertAppError(t, err, domain.CodePRMerged)
+
+		events, err := svc.GetPullRequestHistory(ctx, "pr-1")
+		require.NoError(t, err)
+
+		var reviewed []domain.ReviewDecision
+		for _, event := range events {
+			if event.EventType == domain.PullRequestEventReviewed {
+				reviewed = append(reviewed, *event.Decision)
+			}
+		}
+		assert.Equal(t, []domain.ReviewDecision{
+			domain.ReviewDecisionApproved,
+			domain.ReviewDecisionChangesRequested,
+			domain.ReviewDecisionApproved,
+		}, reviewed)
+	})
+
+	t.Run("merge without approvals when the team does not require them", func(t *testing.T) {
+		pr, err := svc.MergePullRequest(ctx, "pr-relaxed")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusMerged, pr.Status)
+	})
+}
@@ -3,58 +3,148 @@ package service
 import (
 	"ReilBleem13/pull_requests_service/internal/domain"
 	"context"
-	"database/sql"
-	"errors"
-	"math/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
 
 	"github.com/theartofdevel/logging"
 )
 
-func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error) {
-	s.logger.Info("attempt to create pr",
-		logging.StringAttr("prID", prID),
-		logging.StringAttr("prName", prName),
-		logging.StringAttr("authorID", authorID),
-	)
+// CreatePullRequest creates a PR in teamName, which must be one of the author's
+// teams. teamName may be empty only if the author belongs to a single team.
+func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID, teamName string) (*domain.PullRequest, error) {
+	return s.createPullRequest(ctx, prID, prName, authorID, teamName, false, nil)
+}
 
-	if prID == "" {
-		s.logger.Error("failed to create pull request")
-		return nil, domain.ErrInvalidRequest("pr_id is empty")
+// CreatePullRequestIdempotent creates a PR like CreatePullRequest, remembering
+// the result under idempotencyKey. A draft PR gets no reviewers until it is
+// marked ready for review. Keys are scoped to the actor of the request. A
+// retry with the same key and body gets the original PR back with replayed
+// set; the same key with a different body fails with IDEMPOTENCY_KEY_REUSED.
+// An empty key disables the check.
+func (s *Service) CreatePullRequestIdempotent(
+	ctx context.Context,
+	idempotencyKey, prID, prName, authorID, teamName string,
+	draft bool,
+) (pr *domain.PullRequest, replayed bool, err error) {
+	if idempotencyKey == "" {
+		pr, err = s.createPullRequest(ctx, prID, prName, authorID, teamName, draft, nil)
+		return pr, false, err
 	}
 
-	if prName == "" {
-		s.logger.Error("failed to create pull request",
-			logging.StringAttr("prID", prID),
+	requestHash, err := createRequestHash(prID, prName, authorID, teamName, draft)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pr, err = s.replayCreate(ctx, idempotencyKey, requestHash)
+	if err != nil || pr != nil {
+		return pr, pr != nil, err
+	}
+
+	record := &domain.IdempotencyRecord{
+		ActorID:     domain.ActorFromContext(ctx),
+		Key:         idempotencyKey,
+		RequestHash: requestHash,
+	}
+	pr, err = s.createPullRequest(ctx, prID, prName, authorID, teamName, draft, record)
+	if domain.HasCode(err, domain.CodePRExists) || domain.HasCode(err, domain.CodeIdempotencyKeyReused) {
+		// параллельный повтор с тем же ключом мог успеть создать PR раньше
+		replayedPR, replayErr := s.replayCreate(ctx, idempotencyKey, requestHash)
+		if replayErr != nil {
+			return nil, false, replayErr
+		}
+		if replayedPR != nil {
+			return replayedPR, true, nil
+		}
+	}
+	return pr, false, err
+}
+
+// replayCreate returns the PR saved for the key, or nil if the key is unused.
+func (s *Service) replayCreate(ctx context.Context, idempotencyKey, requestHash string) (*domain.PullRequest, error) {
+	record, err := s.prs.GetIdempotencyRecord(ctx, domain.ActorFromContext(ctx), idempotencyKey)
+	if err != nil {
+		s.log(ctx).Error("failed to get idempotency record",
+			logging.StringAttr("idempotencyKey", idempotencyKey),
+			logging.ErrAttr(err),
 		)
-		return nil, domain.ErrInvalidRequest("pr_name is empty")
+		return nil, err
 	}
 
-	if authorID == "" {
-		s.logger.Error("failed to create pull request",
-			logging.StringAttr("prID", prID),
-			logging.StringAttr("prName", prName),
+	if record == nil {
+		return nil, nil
+	}
+
+	if record.RequestHash != requestHash {
+		s.log(ctx).Error("idempotency key was reused with a different request",
+			logging.StringAttr("idempotencyKey", idempotencyKey),
 		)
-		return nil, domain.ErrInvalidRequest("author_id is empty")
+		return nil, domain.ErrIdempotencyKeyReused()
 	}
 
-	_, err := s.users.GetUser(ctx, authorID)
+	var pullRequest domain.PullRequest
+	if err := json.Unmarshal(record.Response, &pullRequest); err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("pr creation was replayed",
+		logging.StringAttr("idempotencyKey", idempotencyKey),
+		logging.StringAttr("prID", pullRequest.PullRequestID),
+	)
+	return &pullRequest, nil
+}
+
+func createRequestHash(prID, prName, authorID, teamName string, draft bool) (string, error) {
+	// необязательные поля добавляются, только если заданы, чтобы хэш совпадал
+	// с ключами, сохранёнными до их появления
+	fields := []string{prID, prName, authorID}
+	if teamName != "" {
+		fields = append(fields, teamName)
+	}
+	if draft {
+		fields = append(fields, "draft")
+	}
+
+	body, err := json.Marshal(fields)
 	if err != nil {
-		s.logger.Error("failed to create pr",
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *Service) createPullRequest(
+	ctx context.Context,
+	prID, prName, authorID, teamName string,
+	draft bool,
+	idempotency *domain.IdempotencyRecord,
+) (*domain.PullRequest, error) {
+	s.log(ctx).Info("attempt to create pr",
+		logging.StringAttr("prID", prID),
+		logging.StringAttr("prName", prName),
+		logging.StringAttr("authorID", authorID),
+		logging.BoolAttr("draft", draft),
+	)
+
+	var v domain.Validation
+	v.ID("pull_request_id", prID)
+	v.Name("pull_request_name", prName)
+	v.Required("author_id", authorID)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to create pull request",
 			logging.StringAttr("prID", prID),
-			logging.StringAttr("prName", prName),
-			logging.StringAttr("authorID", authorID),
 			logging.ErrAttr(err),
 		)
-
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, domain.ErrNotFound()
-		}
 		return nil, err
 	}
 
-	teamName, err := s.users.GetTeamName(ctx, authorID)
+	_, err := s.users.GetUser(ctx, authorID)
 	if err != nil {
-		s.logger.Error("failed to create pr",
+		s.log(ctx).Error("failed to create pr",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("prName", prName),
 			logging.StringAttr("authorID", authorID),
@@ -63,24 +153,53 @@ func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID
 		return nil, err
 	}
 
-	candidates, err := s.prs.GetActiveTeamMembers(ctx, teamName, authorID)
+	teamName, err = s.resolveAuthorTeam(ctx, authorID, teamName)
 	if err != nil {
-		s.logger.Error("failed to create pr",
+		s.log(ctx).Error("failed to create pr",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("prName", prName),
 			logging.StringAttr("authorID", authorID),
+			logging.StringAttr("teamName", teamName),
 			logging.ErrAttr(err),
 		)
 		return nil, err
 	}
 
-	assignedUsers := make([]string, 0, 2)
-	for i := 0; i < len(candidates) && i < 2; i++ {
-		assignedUsers = append(assignedUsers, candidates[i].UserID)
+	status := domain.PRStatusOpen
+	reviewers := make([]domain.Reviewer, 0)
+	if draft {
+		status = domain.PRStatusDraft
+	} else {
+		reviewers, err = s.selectInitialReviewers(ctx, teamName, authorID)
+		if err != nil {
+			s.log(ctx).Error("failed to create pr, failed to select reviewers",
+				logging.StringAttr("prID", prID),
+				logging.StringAttr("teamName", teamName),
+				logging.ErrAttr(err),
+			)
+			return nil, err
+		}
+	}
+
+	pullRequest := &domain.PullRequest{
+		PullRequestID:     prID,
+		PullRequestName:   prName,
+		AuthorID:          authorID,
+		TeamName:          teamName,
+		Status:            status,
+		AssignedReviewers: domain.ReviewerIDs(reviewers),
+		Reviewers:         reviewers,
 	}
 
-	if err := s.prs.Create(ctx, prID, prName, authorID, assignedUsers); err != nil {
-		s.logger.Error("failed to create pr",
+	if idempotency != nil {
+		idempotency.Response, err = json.Marshal(pullRequest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.prs.Create(ctx, prID, prName, authorID, teamName, status, reviewers, idempotency); err != nil {
+		s.log(ctx).Error("failed to create pr",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("prName", prName),
 			logging.StringAttr("authorID", authorID),
@@ -89,74 +208,152 @@ func (s *Service) CreatePullRequest(ctx context.Context, prID, prName, authorID
 		return nil, err
 	}
 
-	s.logger.Info("pr was successfully created",
+	s.metrics.PullRequestCreated()
+	s.log(ctx).Info("pr was successfully created",
 		logging.StringAttr("prID", prID),
 		logging.StringAttr("prName", prName),
 		logging.StringAttr("authorID", authorID),
+		logging.StringAttr("teamName", teamName),
 	)
-	return &domain.PullRequest{
-		PullRequestID:     prID,
-		PullRequestName:   prName,
-		AuthorID:          authorID,
-		Status:            domain.PRStatusOpen,
-		AssignedReviewers: assignedUsers,
-	}, nil
+	return pullRequest, nil
 }
 
-func (s *Service) MergePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	s.logger.Info("attempt to merge pr",
+// resolveAuthorTeam checks that teamName is one of the author's teams. An empty
+// teamName is resolved to the author's first team by name, which is the team
+// PRs were created in before authors could choose one.
+func (s *Service) resolveAuthorTeam(ctx context.Context, authorID, teamName string) (string, error) {
+	teamNames, err := s.users.GetTeamNames(ctx, authorID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(teamNames) == 0:
+		return "", fmt.Errorf("author %s is not a member of any team: %w", authorID, domain.ErrNotFound())
+	case teamName != "":
+		if !slices.Contains(teamNames, teamName) {
+			return "", domain.ErrValidation(domain.FieldError{
+				Field:   "team_name",
+				Rule:    domain.RuleInvalid,
+				Message: "author is not a member of team_name",
+			})
+		}
+		return teamName, nil
+	}
+	return teamNames[0], nil
+}
+
+// validatePullRequestID is the check of the operations on a single PR.
+func validatePullRequestID(prID string) error {
+	var v domain.Validation
+	v.Required("pull_request_id", prID)
+	return v.Err()
+}
+
+func (s *Service) GetPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	s.log(ctx).Info("attempt to get pr",
 		logging.StringAttr("prID", prID),
 	)
 
-	if prID == "" {
-		s.logger.Error("failed to create pull request")
-		return nil, domain.ErrInvalidRequest("pr_id is empty")
+	if err := validatePullRequestID(prID); err != nil {
+		s.log(ctx).Error("failed to get pull request", logging.ErrAttr(err))
+		return nil, err
 	}
 
-	err := s.prs.Merge(ctx, prID)
+	pullRequest, err := s.prs.GetPullRequest(ctx, prID)
 	if err != nil {
-		s.logger.Error("failed to merge pr",
+		s.log(ctx).Error("failed to get pull request",
 			logging.StringAttr("prID", prID),
+			logging.ErrAttr(err),
 		)
 		return nil, err
 	}
 
-	pullRequest, err := s.prs.GetPullRequest(ctx, prID)
+	s.log(ctx).Info("pr was successfully received",
+		logging.StringAttr("prID", prID),
+	)
+	return pullRequest, nil
+}
+
+func (s *Service) GetPullRequestHistory(ctx context.Context, prID string) ([]domain.PullRequestEvent, error) {
+	s.log(ctx).Info("attempt to get pr history",
+		logging.StringAttr("prID", prID),
+	)
+
+	if err := validatePullRequestID(prID); err != nil {
+		s.log(ctx).Error("failed to get pull request history", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	events, err := s.prs.GetHistory(ctx, prID)
 	if err != nil {
-		s.logger.Error("failed to get pull request",
+		s.log(ctx).Error("failed to get pull request history",
 			logging.StringAttr("prID", prID),
+			logging.ErrAttr(err),
 		)
 		return nil, err
 	}
 
-	s.logger.Info("pr was successfully merged",
+	s.log(ctx).Info("pr history was successfully received",
 		logging.StringAttr("prID", prID),
+		logging.IntAttr("events", len(events)),
 	)
+	return events, nil
+}
+
+func (s *Service) MergePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	s.log(ctx).Info("attempt to merge pr",
+		logging.StringAttr("prID", prID),
+	)
+
+	if err := validatePullRequestID(prID); err != nil {
+		s.log(ctx).Error("failed to merge pull request", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	pullRequest, merged, err := s.prs.Merge(ctx, prID)
+	if err != nil {
+		s.log(ctx).Error("failed to merge pr",
+			logging.StringAttr("prID", prID),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	if !merged {
+		s.log(ctx).Info("pr was already merged",
+			logging.StringAttr("prID", prID),
+		)
+		return pullRequest, nil
+	}
 
+	s.metrics.PullRequestMerged()
+	s.log(ctx).Info("pr was successfully merged",
+		logging.StringAttr("prID", prID),
+	)
 	return pullRequest, nil
 }
 
 func (s *Service) ReAssign(ctx context.Context, prID, oldReviewerID string) (*domain.PullRequest, string, error) {
-	s.logger.Info("attempt to reassign",
+	s.log(ctx).Info("attempt to reassign",
 		logging.StringAttr("prID", prID),
 		logging.StringAttr("oldReviewerID", oldReviewerID),
 	)
 
-	if prID == "" {
-		s.logger.Error("failed to reassign")
-		return nil, "", domain.ErrInvalidRequest("pr_id is empty")
-	}
-
-	if oldReviewerID == "" {
-		s.logger.Error("failed to reassign",
+	var v domain.Validation
+	v.Required("pull_request_id", prID)
+	v.Required("old_user_id", oldReviewerID)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to reassign",
 			logging.StringAttr("prID", prID),
+			logging.ErrAttr(err),
 		)
-		return nil, "", domain.ErrInvalidRequest("old_reviewer_id is empty")
+		return nil, "", err
 	}
 
 	pullRequest, err := s.prs.GetPullRequest(ctx, prID)
 	if err != nil {
-		s.logger.Error("failed to reassign",
+		s.log(ctx).Error("failed to reassign",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 		)
@@ -164,7 +361,7 @@ func (s *Service) ReAssign(ctx context.Context, prID, oldReviewerID string) (*do
 	}
 
 	if pullRequest.Status == domain.PRStatusMerged {
-		s.logger.Error("failed to reassign, pr already merged",
+		s.log(ctx).Error("failed to reassign, pr already merged",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 		)
@@ -180,67 +377,48 @@ func (s *Service) ReAssign(ctx context.Context, prID, oldReviewerID string) (*do
 	}
 
 	if !isOldIn {
-		s.logger.Error("failed to reassign, old reviewer is not assigned",
+		s.log(ctx).Error("failed to reassign, old reviewer is not assigned",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 		)
 		return nil, "", domain.ErrNotAssigned()
 	}
 
-	teamName, err := s.users.GetTeamName(ctx, oldReviewerID)
+	settings, err := s.teams.GetSettings(ctx, pullRequest.TeamName)
 	if err != nil {
-		s.logger.Error("failed to reassign, failed to get team name",
+		s.log(ctx).Error("failed to reassign, failed to get team settings",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 		)
 		return nil, "", err
 	}
 
-	teamMembers, err := s.teams.Get(ctx, teamName)
+	// reassign is one-for-one: the replacement takes the old reviewer's place
+	// even if the team now requires more reviewers than the PR has
+	source := s.reviewerSource(settings.ReviewerStrategy)
+
+	newReviewers, err := s.pickReviewers(ctx, source, settings, pullRequest.AuthorID, pullRequest.AssignedReviewers, 1)
 	if err != nil {
-		s.logger.Error("failed to reassign, failed to get team members",
+		s.log(ctx).Error("failed to reassign, failed to select reviewer",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
+			logging.ErrAttr(err),
 		)
 		return nil, "", err
 	}
 
-	candidates := make([]string, 0)
-	for _, tm := range teamMembers {
-		if tm.UserID == oldReviewerID {
-			continue
-		}
-
-		alreadyAssigned := false
-		for _, r := range pullRequest.AssignedReviewers {
-			if r == tm.UserID {
-				alreadyAssigned = true
-				break
-			}
-		}
-
-		if alreadyAssigned || tm.UserID == pullRequest.AuthorID {
-			continue
-		}
-
-		if !tm.IsActive {
-			continue
-		}
-		candidates = append(candidates, tm.UserID)
-	}
-
-	if len(candidates) == 0 {
-		s.logger.Error("failed to reassign, failed to get team members",
+	if len(newReviewers) == 0 {
+		s.metrics.NoCandidate(1)
+		s.log(ctx).Error("failed to reassign, no active candidates",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 		)
 		return nil, "", domain.ErrNoCandidate()
 	}
+	newReviewerID := newReviewers[0].UserID
 
-	newReviewerID := candidates[rand.Intn(len(candidates))]
-
-	if err := s.prs.ReAssign(ctx, prID, oldReviewerID, newReviewerID); err != nil {
-		s.logger.Error("failed to reassign, failed to replace reviewers",
+	if err := s.prs.ReAssign(ctx, prID, oldReviewerID, newReviewers[0]); err != nil {
+		s.log(ctx).Error("failed to reassign, failed to replace reviewers",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 			logging.StringAttr("newReviewerID", newReviewerID),
@@ -250,14 +428,15 @@ func (s *Service) ReAssign(ctx context.Context, prID, oldReviewerID string) (*do
 
 	updatedPullRequest, err := s.prs.GetPullRequest(ctx, prID)
 	if err != nil {
-		s.logger.Error("failed to reassign",
+		s.log(ctx).Error("failed to reassign",
 			logging.StringAttr("prID", prID),
 			logging.StringAttr("oldReviewerID", oldReviewerID),
 		)
 		return nil, "", err
 	}
 
-	s.logger.Info("pr was successfully reassigned",
+	s.metrics.ReviewersReassigned(1)
+	s.log(ctx).Info("pr was successfully reassigned",
 		logging.StringAttr("prID", prID),
 		logging.StringAttr("oldReviewerID", oldReviewerID),
 		logging.StringAttr("newReviewerID", newReviewerID),
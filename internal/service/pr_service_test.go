@@ -5,7 +5,9 @@ package service_test
 import (
 	"context"
 	"math/rand"
+	"sync"
 	"testing"
+	"time"
 
 	"ReilBleem13/pull_requests_service/internal/domain"
 	"ReilBleem13/pull_requests_service/internal/repository"
@@ -22,7 +24,7 @@ func TestService_CreatePullRequest_Integration(t *testing.T) {
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
 
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	_, err := db.Exec(`
@@ -42,7 +44,7 @@ func TestService_CreatePullRequest_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("successfully create PR and assign up to 2 reviewers", func(t *testing.T) {
-		pr, err := svc.CreatePullRequest(ctx, "pr-001", "Fix login", "author-1")
+		pr, err := svc.CreatePullRequest(ctx, "pr-001", "Fix login", "author-1", "")
 
 		assert.NoError(t, err)
 		assert.Equal(t, "pr-001", pr.PullRequestID)
@@ -51,45 +53,419 @@ func TestService_CreatePullRequest_Integration(t *testing.T) {
 		assert.NotContains(t, pr.AssignedReviewers, "author-1")
 	})
 
+	t.Run("prefer reviewers with fewer open assignments", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-006", "Add search", "author-1", "")
+
+		assert.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, 2)
+		assert.Contains(t, pr.AssignedReviewers, "rev-3")
+	})
+
 	t.Run("fail on empty prID", func(t *testing.T) {
-		pr, err := svc.CreatePullRequest(ctx, "", "Title", "author-1")
+		pr, err := svc.CreatePullRequest(ctx, "", "Title", "author-1", "")
 		assert.Error(t, err)
 		assert.Nil(t, pr)
-		assertAppError(t, err, domain.CodeInvalidRequest, "pr_id is empty")
+		assertAppError(t, err, domain.CodeInvalidRequest, "pull_request_id is empty")
 	})
 
 	t.Run("fail on empty prName", func(t *testing.T) {
-		pr, err := svc.CreatePullRequest(ctx, "pr-002", "", "author-1")
+		pr, err := svc.CreatePullRequest(ctx, "pr-002", "", "author-1", "")
 		assert.Error(t, err)
 		assert.Nil(t, pr)
-		assertAppError(t, err, domain.CodeInvalidRequest, "pr_name is empty")
+		assertAppError(t, err, domain.CodeInvalidRequest, "pull_request_name is empty")
 	})
 
 	t.Run("fail on empty authorID", func(t *testing.T) {
-		pr, err := svc.CreatePullRequest(ctx, "pr-003", "Title", "")
+		pr, err := svc.CreatePullRequest(ctx, "pr-003", "Title", "", "")
 		assert.Error(t, err)
 		assert.Nil(t, pr)
 		assertAppError(t, err, domain.CodeInvalidRequest, "author_id is empty")
 	})
 
+	t.Run("report every invalid field at once", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr 005", "", "", "")
+
+		var appErr *domain.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, domain.CodeInvalidRequest, appErr.Code)
+		assert.Equal(t, []domain.FieldError{
+			{Field: "pull_request_id", Rule: domain.RuleCharset, Message: "pull_request_id may contain only letters, digits, '.', '_' and '-'"},
+			{Field: "pull_request_name", Rule: domain.RuleRequired, Message: "pull_request_name is empty"},
+			{Field: "author_id", Rule: domain.RuleRequired, Message: "author_id is empty"},
+		}, appErr.Details)
+	})
+
 	t.Run("fail when author not found", func(t *testing.T) {
-		pr, err := svc.CreatePullRequest(ctx, "pr-004", "Title", "ghost")
+		pr, err := svc.CreatePullRequest(ctx, "pr-004", "Title", "ghost", "")
 		assert.Error(t, err)
 		assert.Nil(t, pr)
 		assertAppError(t, err, domain.CodeNotFound)
 	})
 
+	t.Run("assign as many reviewers as the team requires", func(t *testing.T) {
+		_, err := db.Exec(`UPDATE teams SET required_reviewers = 1 WHERE team_name = 'backend'`)
+		require.NoError(t, err)
+		defer db.Exec(`UPDATE teams SET required_reviewers = 2 WHERE team_name = 'backend'`)
+
+		pr, err := svc.CreatePullRequest(ctx, "pr-007", "Single reviewer", "author-1", "")
+		assert.NoError(t, err)
+		assert.Len(t, pr.AssignedReviewers, 1)
+	})
+
 	t.Run("assign fewer than 2 if not enough active members", func(t *testing.T) {
 		_, err := db.Exec(`UPDATE users SET is_active = false WHERE user_id IN ('rev-2', 'rev-3')`)
 		require.NoError(t, err)
 
-		pr, err := svc.CreatePullRequest(ctx, "pr-005", "Only one reviewer", "author-1")
+		pr, err := svc.CreatePullRequest(ctx, "pr-005", "Only one reviewer", "author-1", "")
 		assert.NoError(t, err)
 		assert.Len(t, pr.AssignedReviewers, 1)
 		assert.Equal(t, "rev-1", pr.AssignedReviewers[0])
 	})
 }
 
+func TestService_CreatePullRequest_FallbackPool_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('solo'), ('platform'), ('infra');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author-1', 'alice', true),
+		('mate-1', 'bob', true),
+		('platform-1', 'charlie', true),
+		('infra-1', 'dave', true),
+		('infra-2', 'eve', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('solo', 'author-1'),
+		('solo', 'mate-1'),
+		('platform', 'platform-1'),
+		('infra', 'infra-1'),
+		('infra', 'infra-2');
+	`)
+	require.NoError(t, err)
+
+	t.Run("assign only home team members without fallback pool", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-no-fallback", "Fix typo", "author-1", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Reviewer{{UserID: "mate-1", TeamName: "solo"}}, pr.Reviewers)
+	})
+
+	t.Run("fill missing reviewers from fallback teams in priority order", func(t *testing.T) {
+		required := 3
+		_, err := svc.UpdateTeamSettings(ctx, "solo", domain.TeamSettingsUpdate{
+			RequiredReviewers: &required,
+			FallbackTeams:     []string{"platform", "infra"},
+		})
+		require.NoError(t, err)
+
+		pr, err := svc.CreatePullRequest(ctx, "pr-fallback", "Add cache", "author-1", "")
+
+		require.NoError(t, err)
+		require.Len(t, pr.Reviewers, 3)
+		assert.Equal(t, domain.Reviewer{UserID: "mate-1", TeamName: "solo"}, pr.Reviewers[0])
+		assert.Equal(t, domain.Reviewer{UserID: "platform-1", TeamName: "platform"}, pr.Reviewers[1])
+		assert.Equal(t, "infra", pr.Reviewers[2].TeamName)
+	})
+
+	t.Run("reassign to fallback team member", func(t *testing.T) {
+		pr, newID, err := svc.ReAssign(ctx, "pr-fallback", "mate-1")
+
+		require.NoError(t, err)
+		assert.Contains(t, []string{"infra-1", "infra-2"}, newID)
+		assert.NotContains(t, pr.AssignedReviewers, "mate-1")
+		for _, r := range pr.Reviewers {
+			if r.UserID == newID {
+				assert.Equal(t, "infra", r.TeamName)
+			}
+		}
+	})
+
+	t.Run("fail on unknown fallback team", func(t *testing.T) {
+		_, err := svc.UpdateTeamSettings(ctx, "solo", domain.TeamSettingsUpdate{FallbackTeams: []string{"ghost"}})
+
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+}
+
+func TestService_CreatePullRequestIdempotent_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'alice', true),
+		('rev-1', 'bob', true),
+		('rev-2', 'carol', true),
+		('rev-3', 'dave', true);
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2'),
+		('backend', 'rev-3');
+	`)
+	require.NoError(t, err)
+
+	t.Run("replay identical retry", func(t *testing.T) {
+		created, replayed, err := svc.CreatePullRequestIdempotent(ctx, "key-1", "pr-idem", "Idempotent", "author", "", false)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+
+		again, replayed, err := svc.CreatePullRequestIdempotent(ctx, "key-1", "pr-idem", "Idempotent", "author", "", false)
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, created, again)
+
+		var events int
+		require.NoError(t, db.Get(&events,
+			`SELECT COUNT(*) FROM pull_request_events WHERE pull_request_id = 'pr-idem' AND event_type = 'CREATED'`))
+		assert.Equal(t, 1, events)
+	})
+
+	t.Run("fail on reused key with different body", func(t *testing.T) {
+		_, _, err := svc.CreatePullRequestIdempotent(ctx, "key-1", "pr-other", "Other", "author", "", false)
+		assertAppError(t, err, domain.CodeIdempotencyKeyReused)
+
+		_, err = svc.GetPullRequest(ctx, "pr-other")
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("PR_EXISTS for existing PR with a new key", func(t *testing.T) {
+		_, _, err := svc.CreatePullRequestIdempotent(ctx, "key-2", "pr-idem", "Idempotent", "author", "", false)
+		assertAppError(t, err, domain.CodePRExists)
+
+		// ключ неудачного запроса не сохраняется
+		var keys int
+		require.NoError(t, db.Get(&keys, `SELECT COUNT(*) FROM idempotency_keys WHERE idempotency_key = 'key-2'`))
+		assert.Zero(t, keys)
+	})
+
+	t.Run("concurrent retries create PR once", func(t *testing.T) {
+		const retries = 5
+
+		var wg sync.WaitGroup
+		results := make([]bool, retries)
+		errs := make([]error, retries)
+		for i := range retries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, results[i], errs[i] = svc.CreatePullRequestIdempotent(ctx, "key-3", "pr-race", "Race", "author", "", false)
+			}()
+		}
+		wg.Wait()
+
+		created := 0
+		for i := range retries {
+			require.NoError(t, errs[i])
+			if !results[i] {
+				created++
+			}
+		}
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("no key keeps PR_EXISTS", func(t *testing.T) {
+		_, replayed, err := svc.CreatePullRequestIdempotent(ctx, "", "pr-idem", "Idempotent", "author", "", false)
+		assert.False(t, replayed)
+		assertAppError(t, err, domain.CodePRExists)
+	})
+
+	t.Run("scope keys to the actor", func(t *testing.T) {
+		botA := domain.ContextWithActor(ctx, "bot-a")
+		botB := domain.ContextWithActor(ctx, "bot-b")
+
+		// both bots use the build number as the key
+		_, replayed, err := svc.CreatePullRequestIdempotent(botA, "build-7", "pr-bot-a", "Bot A", "author", "", false)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+
+		pr, replayed, err := svc.CreatePullRequestIdempotent(botB, "build-7", "pr-bot-b", "Bot B", "author", "", false)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, "pr-bot-b", pr.PullRequestID)
+
+		pr, replayed, err = svc.CreatePullRequestIdempotent(botA, "build-7", "pr-bot-a", "Bot A", "author", "", false)
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, "pr-bot-a", pr.PullRequestID)
+	})
+
+	t.Run("purge expired keys", func(t *testing.T) {
+		_, err := db.Exec(`
+			UPDATE idempotency_keys SET created_at = NOW() - INTERVAL '25 hours'
+			WHERE actor_id = 'bot-a' AND idempotency_key = 'build-7'`)
+		require.NoError(t, err)
+
+		purged, err := svc.PurgeIdempotencyKeys(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		// the expired key can be used for another request
+		botA := domain.ContextWithActor(ctx, "bot-a")
+		pr, replayed, err := svc.CreatePullRequestIdempotent(botA, "build-7", "pr-bot-a-2", "Bot A again", "author", "", false)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, "pr-bot-a-2", pr.PullRequestID)
+	})
+}
+
+func TestService_GetPullRequest_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author-1', 'alice', true),
+		('rev-1', 'bob', true);
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author-1'),
+		('backend', 'rev-1');
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status, created_at, merged_at)
+		VALUES ('pr-merged', 'Merged PR', 'author-1', 'backend', 'MERGED', '2025-01-01T10:00:00Z', '2025-01-01T12:00:00Z');
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name, assigned_at)
+		VALUES ('pr-merged', 'rev-1', 'backend', '2025-01-01T10:00:01Z');
+	`)
+	require.NoError(t, err)
+
+	t.Run("return PR with timestamps and reviewers", func(t *testing.T) {
+		pr, err := svc.GetPullRequest(ctx, "pr-merged")
+		require.NoError(t, err)
+
+		require.NotNil(t, pr.CreatedAt)
+		require.NotNil(t, pr.MergedAt)
+		assert.True(t, pr.CreatedAt.Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)))
+		assert.True(t, pr.MergedAt.Equal(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+		require.Len(t, pr.Reviewers, 1)
+		assert.Equal(t, "rev-1", pr.Reviewers[0].UserID)
+		assert.Equal(t, "backend", pr.Reviewers[0].TeamName)
+		require.NotNil(t, pr.Reviewers[0].AssignedAt)
+		assert.True(t, pr.Reviewers[0].AssignedAt.Equal(time.Date(2025, 1, 1, 10, 0, 1, 0, time.UTC)))
+		assert.Equal(t, []string{"rev-1"}, pr.AssignedReviewers)
+	})
+
+	t.Run("fail on empty prID", func(t *testing.T) {
+		_, err := svc.GetPullRequest(ctx, "")
+		assertAppError(t, err, domain.CodeInvalidRequest, "pull_request_id is empty")
+	})
+
+	t.Run("fail when PR not found", func(t *testing.T) {
+		_, err := svc.GetPullRequest(ctx, "ghost-pr")
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+}
+
+func TestService_GetPullRequestHistory_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := domain.ContextWithActor(context.Background(), "admin")
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name, required_reviewers) VALUES ('backend', 1);
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'alice', true),
+		('rev-1', 'bob', true),
+		('rev-2', 'carol', false);
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2');
+	`)
+	require.NoError(t, err)
+
+	eventTypes := func(events []domain.PullRequestEvent) []domain.PullRequestEventType {
+		types := make([]domain.PullRequestEventType, 0, len(events))
+		for _, e := range events {
+			types = append(types, e.EventType)
+		}
+		return types
+	}
+
+	t.Run("record create, activity, reassign and merge", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr-history", "History", "author", "")
+		require.NoError(t, err)
+
+		// повторная установка того же значения не попадает в историю
+		_, _, err = svc.SetIsActive(ctx, "rev-1", true)
+		require.NoError(t, err)
+
+		_, _, err = svc.SetIsActive(ctx, "rev-2", true)
+		require.NoError(t, err)
+
+		_, newID, err := svc.ReAssign(ctx, "pr-history", "rev-1")
+		require.NoError(t, err)
+		assert.Equal(t, "rev-2", newID)
+
+		_, _, err = svc.SetIsActive(ctx, "rev-2", false)
+		require.NoError(t, err)
+
+		_, err = svc.MergePullRequest(ctx, "pr-history")
+		require.NoError(t, err)
+		_, err = svc.MergePullRequest(ctx, "pr-history")
+		require.NoError(t, err)
+
+		events, err := svc.GetPullRequestHistory(ctx, "pr-history")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.PullRequestEventType{
+			domain.PullRequestEventCreated,
+			domain.PullRequestEventAssigned,
+			domain.PullRequestEventReassigned,
+			domain.PullRequestEventActivityChanged,
+			domain.PullRequestEventMerged,
+		}, eventTypes(events))
+
+		assert.Equal(t, "rev-1", *events[1].ReviewerID)
+
+		assert.Equal(t, "rev-2", *events[2].ReviewerID)
+		assert.Equal(t, "rev-1", *events[2].OldReviewerID)
+
+		assert.Equal(t, "rev-2", *events[3].ReviewerID)
+		assert.False(t, *events[3].IsActive)
+
+		for _, e := range events {
+			require.NotNil(t, e.ActorID)
+			assert.Equal(t, "admin", *e.ActorID)
+		}
+	})
+
+	t.Run("fail on empty prID", func(t *testing.T) {
+		_, err := svc.GetPullRequestHistory(ctx, "")
+		assertAppError(t, err, domain.CodeInvalidRequest, "pull_request_id is empty")
+	})
+
+	t.Run("fail when PR not found", func(t *testing.T) {
+		_, err := svc.GetPullRequestHistory(ctx, "ghost-pr")
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+}
+
 func TestService_MergePullRequest_Integration(t *testing.T) {
 	db := setupTestDatabase(t)
 
@@ -97,15 +473,15 @@ func TestService_MergePullRequest_Integration(t *testing.T) {
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
 
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	_, err := db.Exec(`
 		INSERT INTO teams (team_name) VALUES ('backend');
 		INSERT INTO users (user_id, username) VALUES ('author-1', 'alice');
 		INSERT INTO team_members (team_name, user_id) VALUES ('backend', 'author-1');
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status) 
-		VALUES ('pr-open', 'Open PR', 'author-1', 'OPEN');
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status)
+		VALUES ('pr-open', 'Open PR', 'author-1', 'backend', 'OPEN');
 	`)
 	require.NoError(t, err)
 
@@ -117,11 +493,61 @@ func TestService_MergePullRequest_Integration(t *testing.T) {
 		assert.NotEmpty(t, pr.MergedAt)
 	})
 
+	t.Run("repeated merge keeps merged_at", func(t *testing.T) {
+		first, err := svc.MergePullRequest(ctx, "pr-open")
+		require.NoError(t, err)
+
+		second, err := svc.MergePullRequest(ctx, "pr-open")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusMerged, second.Status)
+		assert.True(t, first.MergedAt.Equal(*second.MergedAt))
+	})
+
+	t.Run("parallel merges transition once", func(t *testing.T) {
+		_, err := db.Exec(`
+			INSERT INTO users (user_id, username) VALUES ('rev-1', 'bob');
+			INSERT INTO webhooks (team_name, url, secret) VALUES ('backend', 'http://localhost/hook', 's');
+			INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status)
+			VALUES ('pr-race', 'Race PR', 'author-1', 'backend', 'OPEN');
+			INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES ('pr-race', 'rev-1');
+		`)
+		require.NoError(t, err)
+
+		const calls = 10
+
+		var wg sync.WaitGroup
+		prs := make([]*domain.PullRequest, calls)
+		errs := make([]error, calls)
+		for i := range calls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				prs[i], errs[i] = svc.MergePullRequest(ctx, "pr-race")
+			}()
+		}
+		wg.Wait()
+
+		for i := range calls {
+			require.NoError(t, errs[i])
+			assert.Equal(t, domain.PRStatusMerged, prs[i].Status)
+			assert.True(t, prs[0].MergedAt.Equal(*prs[i].MergedAt))
+			assert.Equal(t, []string{"rev-1"}, prs[i].AssignedReviewers)
+		}
+
+		var events, deliveries int
+		require.NoError(t, db.Get(&events,
+			`SELECT COUNT(*) FROM pull_request_events WHERE pull_request_id = 'pr-race' AND event_type = 'MERGED'`))
+		require.NoError(t, db.Get(&deliveries,
+			`SELECT COUNT(*) FROM webhook_deliveries WHERE event_type = 'pr.merged' AND payload->'pull_request'->>'pull_request_id' = 'pr-race'`))
+		assert.Equal(t, 1, events)
+		assert.Equal(t, 1, deliveries)
+	})
+
 	t.Run("fail on empty prID", func(t *testing.T) {
 		pr, err := svc.MergePullRequest(ctx, "")
 		assert.Error(t, err)
 		assert.Nil(t, pr)
-		assertAppError(t, err, domain.CodeInvalidRequest, "pr_id is empty")
+		assertAppError(t, err, domain.CodeInvalidRequest, "pull_request_id is empty")
 	})
 
 	t.Run("fail when PR not found", func(t *testing.T) {
@@ -140,7 +566,7 @@ func TestService_ReAssign_Integration(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	setupReAssignTest := func(t *testing.T, teamName string) {
@@ -173,9 +599,9 @@ func TestService_ReAssign_Integration(t *testing.T) {
 		exec(`DELETE FROM pull_requests WHERE pull_request_id = 'pr-reassign'`)
 
 		exec(`
-		INSERT INTO pull_requests 
-		(pull_request_id, pull_request_name, author_id, status)
-		VALUES ('pr-reassign', 'Test PR', 'author', 'OPEN')`)
+		INSERT INTO pull_requests
+		(pull_request_id, pull_request_name, author_id, team_name, status)
+		VALUES ('pr-reassign', 'Test PR', 'author', $1, 'OPEN')`, teamName)
 
 		exec(`
 		INSERT INTO pull_request_reviewers (pull_request_id, user_id)
@@ -193,6 +619,17 @@ func TestService_ReAssign_Integration(t *testing.T) {
 		assert.NotContains(t, pr.AssignedReviewers, "old")
 	})
 
+	t.Run("stay one-for-one when team requires more", func(t *testing.T) {
+		setupReAssignTest(t, "team-one-for-one")
+		_, err := db.Exec(`UPDATE teams SET required_reviewers = 3`)
+		require.NoError(t, err)
+		defer db.Exec(`UPDATE teams SET required_reviewers = 2`)
+
+		pr, newID, err := svc.ReAssign(ctx, "pr-reassign", "old")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{newID}, pr.AssignedReviewers)
+	})
+
 	t.Run("fail on merged PR", func(t *testing.T) {
 		setupReAssignTest(t, "team-merged")
 		_, err := svc.MergePullRequest(ctx, "pr-reassign")
@@ -204,6 +641,21 @@ func TestService_ReAssign_Integration(t *testing.T) {
 		assertAppError(t, err, domain.CodePRMerged)
 	})
 
+	t.Run("fail on closed PR", func(t *testing.T) {
+		setupReAssignTest(t, "team-closed")
+		_, err := db.Exec(`UPDATE pull_requests SET status = 'CLOSED' WHERE pull_request_id = 'pr-reassign'`)
+		require.NoError(t, err)
+
+		pr, _, err := svc.ReAssign(ctx, "pr-reassign", "old")
+		assert.Nil(t, pr)
+		assertAppError(t, err, domain.CodeInvalidTransition)
+
+		var reviewers []string
+		require.NoError(t, db.Select(&reviewers,
+			`SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = 'pr-reassign'`))
+		assert.Equal(t, []string{"old"}, reviewers)
+	})
+
 	t.Run("fail when old reviewer not assigned", func(t *testing.T) {
 		setupReAssignTest(t, "team-not-assigned")
 		pr, _, err := svc.ReAssign(ctx, "pr-reassign", "ghost")
@@ -247,8 +699,8 @@ func TestService_ReAssign_Integration(t *testing.T) {
 		require.NoError(t, err)
 
 		_, err = tx.Exec(`
-			INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
-			VALUES ('pr-reassign', 'Test PR', 'author', 'OPEN')
+			INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status)
+			VALUES ('pr-reassign', 'Test PR', 'author', 'team-no-candidate', 'OPEN')
 		`)
 		require.NoError(t, err)
 
@@ -269,3 +721,74 @@ func TestService_ReAssign_Integration(t *testing.T) {
 		assertAppError(t, err, domain.CodeNoCandidate)
 	})
 }
+
+func TestService_CreatePullRequest_MultiTeamAuthor_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name, required_reviewers) VALUES ('backend', 1), ('platform', 1), ('mobile', 1);
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'Author', true),
+		('back-1', 'B1', true),
+		('back-2', 'B2', true),
+		('plat-1', 'P1', true),
+		('plat-2', 'P2', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'back-1'),
+		('backend', 'back-2'),
+		('platform', 'author'),
+		('platform', 'plat-1'),
+		('platform', 'plat-2');
+	`)
+	require.NoError(t, err)
+
+	t.Run("default to the first team by name for author from several teams", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-default-team", "Default team", "author", "")
+		require.NoError(t, err)
+		assert.Equal(t, "backend", pr.TeamName)
+		require.Len(t, pr.AssignedReviewers, 1)
+		assert.Contains(t, []string{"back-1", "back-2"}, pr.AssignedReviewers[0])
+	})
+
+	t.Run("reject team the author is not a member of", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr-foreign", "Foreign", "author", "mobile")
+		assertAppError(t, err, domain.CodeInvalidRequest, "not a member of team_name")
+	})
+
+	t.Run("create PR in the chosen team and reassign within it", func(t *testing.T) {
+		pr, err := svc.CreatePullRequest(ctx, "pr-platform", "Platform", "author", "platform")
+		require.NoError(t, err)
+		assert.Equal(t, "platform", pr.TeamName)
+		require.Len(t, pr.AssignedReviewers, 1)
+		assert.Contains(t, []string{"plat-1", "plat-2"}, pr.AssignedReviewers[0])
+
+		stored, err := svc.GetPullRequest(ctx, "pr-platform")
+		require.NoError(t, err)
+		assert.Equal(t, "platform", stored.TeamName)
+
+		// старый ревьювер состоит и в backend, по алфавиту идущей первой
+		oldReviewer := pr.AssignedReviewers[0]
+		_, err = db.Exec(`INSERT INTO team_members (team_name, user_id) VALUES ('backend', $1)`, oldReviewer)
+		require.NoError(t, err)
+
+		_, newReviewer, err := svc.ReAssign(ctx, "pr-platform", oldReviewer)
+		require.NoError(t, err)
+		assert.Contains(t, []string{"plat-1", "plat-2"}, newReviewer)
+		assert.NotEqual(t, oldReviewer, newReviewer)
+	})
+
+	t.Run("set is active returns all teams", func(t *testing.T) {
+		_, teamNames, err := svc.SetIsActive(ctx, "author", true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"backend", "platform"}, teamNames)
+	})
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+
+	"github.com/theartofdevel/logging"
+)
+
+// ReadyPullRequest moves a draft PR to OPEN and assigns its reviewers.
+func (s *Service) ReadyPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.changeStatus(ctx, prID, domain.PRStatusDraft, domain.PRStatusOpen)
+}
+
+// ClosePullRequest closes a draft or open PR without merging and releases its reviewers.
+func (s *Service) ClosePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.changeStatus(ctx, prID, "", domain.PRStatusClosed)
+}
+
+// ReopenPullRequest moves a closed PR back to OPEN and assigns new reviewers.
+func (s *Service) ReopenPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.changeStatus(ctx, prID, domain.PRStatusClosed, domain.PRStatusOpen)
+}
+
+// changeStatus moves the PR to status to. If from is not empty the PR must
+// currently be in that status, so that ready and reopen can't be mixed up
+// even though both lead to OPEN.
+func (s *Service) changeStatus(
+	ctx context.Context,
+	prID string,
+	from, to domain.PRStatus,
+) (*domain.PullRequest, error) {
+	s.log(ctx).Info("attempt to change pr status",
+		logging.StringAttr("prID", prID),
+		logging.StringAttr("status", string(to)),
+	)
+
+	if err := validatePullRequestID(prID); err != nil {
+		s.log(ctx).Error("failed to change pr status", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	pullRequest, err := s.prs.GetPullRequest(ctx, prID)
+	if err != nil {
+		s.log(ctx).Error("failed to change pr status",
+			logging.StringAttr("prID", prID),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	// a fast check; the repository repeats it under the row lock
+	if !pullRequest.Status.CanTransitionTo(to) || (from != "" && pullRequest.Status != from) {
+		s.log(ctx).Error("failed to change pr status, transition is not allowed",
+			logging.StringAttr("prID", prID),
+			logging.StringAttr("from", string(pullRequest.Status)),
+			logging.StringAttr("to", string(to)),
+		)
+		return nil, domain.ErrInvalidTransition(pullRequest.Status, to)
+	}
+
+	var reviewers []domain.Reviewer
+	if to == domain.PRStatusOpen {
+		reviewers, err = s.selectInitialReviewers(ctx, pullRequest.TeamName, pullRequest.AuthorID)
+		if err != nil {
+			s.log(ctx).Error("failed to change pr status, failed to select reviewers",
+				logging.StringAttr("prID", prID),
+				logging.StringAttr("teamName", pullRequest.TeamName),
+				logging.ErrAttr(err),
+			)
+			return nil, err
+		}
+	}
+
+	pullRequest, err = s.prs.ChangeStatus(ctx, prID, from, to, reviewers)
+	if err != nil {
+		s.log(ctx).Error("failed to change pr status",
+			logging.StringAttr("prID", prID),
+			logging.StringAttr("status", string(to)),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.log(ctx).Info("pr status was successfully changed",
+		logging.StringAttr("prID", prID),
+		logging.StringAttr("status", string(pullRequest.Status)),
+		logging.IntAttr("reviewers", len(pullRequest.Reviewers)),
+	)
+	return pullRequest, nil
+}
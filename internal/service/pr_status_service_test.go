@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/repository"
+	"ReilBleem13/pull_requests_service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PullRequestStatus_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'Author', true),
+		('rev-1', 'R1', true),
+		('rev-2', 'R2', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2');
+	`)
+	require.NoError(t, err)
+
+	t.Run("draft has no reviewers until ready", func(t *testing.T) {
+		pr, _, err := svc.CreatePullRequestIdempotent(ctx, "", "pr-draft", "Draft", "author", "", true)
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusDraft, pr.Status)
+		assert.Empty(t, pr.Reviewers)
+
+		_, err = svc.MergePullRequest(ctx, "pr-draft")
+		assertAppError(t, err, domain.CodeInvalidTransition)
+
+		_, err = svc.ReopenPullRequest(ctx, "pr-draft")
+		assertAppError(t, err, domain.CodeInvalidTransition)
+
+		pr, err = svc.ReadyPullRequest(ctx, "pr-draft")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusOpen, pr.Status)
+		assert.ElementsMatch(t, []string{"rev-1", "rev-2"}, pr.AssignedReviewers)
+
+		_, err = svc.ReadyPullRequest(ctx, "pr-draft")
+		assertAppError(t, err, domain.CodeInvalidTransition)
+	})
+
+	t.Run("close frees reviewers and reopen assigns them again", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr-close", "Close", "author", "")
+		require.NoError(t, err)
+
+		pr, err := svc.ClosePullRequest(ctx, "pr-close")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusClosed, pr.Status)
+		assert.NotNil(t, pr.ClosedAt)
+		assert.Empty(t, pr.Reviewers)
+
+		_, err = svc.MergePullRequest(ctx, "pr-close")
+		assertAppError(t, err, domain.CodeInvalidTransition)
+
+		_, err = svc.ClosePullRequest(ctx, "pr-close")
+		assertAppError(t, err, domain.CodeInvalidTransition)
+
+		pr, err = svc.ReopenPullRequest(ctx, "pr-close")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusOpen, pr.Status)
+		assert.Nil(t, pr.ClosedAt)
+		assert.ElementsMatch(t, []string{"rev-1", "rev-2"}, pr.AssignedReviewers)
+
+		events, err := svc.GetPullRequestHistory(ctx, "pr-close")
+		require.NoError(t, err)
+
+		var statusEvents []domain.PullRequestEventType
+		for _, event := range events {
+			if event.EventType == domain.PullRequestEventClosed || event.EventType == domain.PullRequestEventReopened {
+				statusEvents = append(statusEvents, event.EventType)
+			}
+		}
+		assert.Equal(t, []domain.PullRequestEventType{
+			domain.PullRequestEventClosed,
+			domain.PullRequestEventReopened,
+		}, statusEvents)
+	})
+
+	t.Run("merged PR can't be closed or reopened", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr-merged", "Merged", "author", "")
+		require.NoError(t, err)
+
+		_, err = svc.MergePullRequest(ctx, "pr-merged")
+		require.NoError(t, err)
+
+		_, err = svc.ClosePullRequest(ctx, "pr-merged")
+		assertAppError(t, err, domain.CodeInvalidTransition, "MERGED to CLOSED")
+
+		_, err = svc.ReopenPullRequest(ctx, "pr-merged")
+		assertAppError(t, err, domain.CodeInvalidTransition)
+	})
+
+	t.Run("ready doesn't reopen a PR closed after the check", func(t *testing.T) {
+		_, _, err := svc.CreatePullRequestIdempotent(ctx, "", "pr-race", "Race", "author", "", true)
+		require.NoError(t, err)
+
+		_, err = svc.ClosePullRequest(ctx, "pr-race")
+		require.NoError(t, err)
+
+		// ready checked the PR while it was still a draft
+		_, err = prRepo.ChangeStatus(ctx, "pr-race", domain.PRStatusDraft, domain.PRStatusOpen, nil)
+		assertAppError(t, err, domain.CodeInvalidTransition, "CLOSED to OPEN")
+
+		pr, err := svc.GetPullRequest(ctx, "pr-race")
+		require.NoError(t, err)
+		assert.Equal(t, domain.PRStatusClosed, pr.Status)
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		_, err := svc.ClosePullRequest(ctx, "ghost")
+		assertAppError(t, err, domain.CodeNotFound)
+
+		_, err = svc.ReadyPullRequest(ctx, "")
+		assertAppError(t, err, domain.CodeInvalidRequest)
+	})
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"slices"
+)
+
+// batchAssignmentCounter serves open assignment counts from memory, so a batch
+// of selections loads them once and sees the assignments it has already planned.
+type batchAssignmentCounter struct {
+	counter OpenAssignmentsCounter
+	counts  map[string]int
+}
+
+func (b *batchAssignmentCounter) CountOpenAssignments(ctx context.Context, userIDs []string) (map[string]int, error) {
+	missing := make([]string, 0)
+	for _, userID := range userIDs {
+		if _, ok := b.counts[userID]; !ok {
+			missing = append(missing, userID)
+		}
+	}
+
+	if len(missing) > 0 {
+		counts, err := b.counter.CountOpenAssignments(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, userID := range missing {
+			b.counts[userID] = counts[userID]
+		}
+	}
+	return b.counts, nil
+}
+
+// batchReviewerSource caches team pools for the whole batch and, for the
+// least loaded strategy, keeps the assignment counts up to date in memory.
+func (s *Service) batchReviewerSource(strategy domain.ReviewerStrategy) (*reviewerSource, func(userID string)) {
+	cache := make(map[string][]domain.User)
+	source := &reviewerSource{
+		members: func(ctx context.Context, teamName string) ([]domain.User, error) {
+			if members, ok := cache[teamName]; ok {
+				return members, nil
+			}
+			members, err := s.prs.GetActiveTeamMembers(ctx, teamName, "")
+			if err != nil {
+				return nil, err
+			}
+			cache[teamName] = members
+			return members, nil
+		},
+		selector: s.selectorFor(strategy),
+	}
+
+	assigned := func(string) {}
+	if _, ok := source.selector.(*LeastLoadedSelector); ok {
+		counter := &batchAssignmentCounter{counter: s.prs, counts: make(map[string]int)}
+		source.selector = NewLeastLoadedSelector(counter)
+		assigned = func(userID string) { counter.counts[userID]++ }
+	}
+	return source, assigned
+}
+
+// planReassignments picks a replacement for every review of the leaving users on
+// the given open pull requests, following the same rules as ReAssign: every PR
+// takes candidates by the settings of its own team. When fromTeam is set, only
+// reviews taken for that team are replaced. Reviews without an active candidate
+// are reported separately and stay as they are.
+func (s *Service) planReassignments(
+	ctx context.Context,
+	pullRequests []domain.PullRequest,
+	leaving []string,
+	fromTeam string,
+) ([]domain.Reassignment, []domain.UnassignedReview, error) {
+	settingsByTeam := make(map[string]*domain.TeamSettings)
+	sources := make(map[domain.ReviewerStrategy]*reviewerSource)
+	assignedBy := make(map[domain.ReviewerStrategy]func(userID string))
+
+	// a source is made once per strategy, so the counts of the least loaded
+	// strategy are shared by all teams using it
+	reviewerSourceFor := func(teamName string) (*domain.TeamSettings, *reviewerSource, error) {
+		settings, ok := settingsByTeam[teamName]
+		if !ok {
+			var err error
+			settings, err = s.teams.GetSettings(ctx, teamName)
+			if err != nil {
+				return nil, nil, err
+			}
+			settingsByTeam[teamName] = settings
+		}
+
+		source, ok := sources[settings.ReviewerStrategy]
+		if !ok {
+			source, assignedBy[settings.ReviewerStrategy] = s.batchReviewerSource(settings.ReviewerStrategy)
+			sources[settings.ReviewerStrategy] = source
+		}
+		return settings, source, nil
+	}
+
+	reassignments := make([]domain.Reassignment, 0)
+	unassigned := make([]domain.UnassignedReview, 0)
+
+	for _, pr := range pullRequests {
+		current := slices.Clone(pr.AssignedReviewers)
+
+		for _, reviewer := range pr.Reviewers {
+			reviewerID := reviewer.UserID
+			if !slices.Contains(leaving, reviewerID) {
+				continue
+			}
+
+			// у старых назначений команда не записана, их считаем назначениями fromTeam
+			if fromTeam != "" && reviewer.TeamName != "" && reviewer.TeamName != fromTeam {
+				continue
+			}
+
+			settings, source, err := reviewerSourceFor(pr.TeamName)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			exclude := append(slices.Clone(current), leaving...)
+			picked, err := s.pickReviewers(ctx, source, settings, pr.AuthorID, exclude, 1)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if len(picked) == 0 {
+				unassigned = append(unassigned, domain.UnassignedReview{
+					PullRequestID: pr.PullRequestID,
+					ReviewerID:    reviewerID,
+				})
+				continue
+			}
+
+			assignedBy[settings.ReviewerStrategy](picked[0].UserID)
+			current = append(current, picked[0].UserID)
+			reassignments = append(reassignments, domain.Reassignment{
+				PullRequestID: pr.PullRequestID,
+				OldReviewerID: reviewerID,
+				NewReviewerID: picked[0].UserID,
+				TeamName:      picked[0].TeamName,
+			})
+		}
+	}
+	return reassignments, unassigned, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// ReviewerSelector picks up to count reviewers out of the active candidates of a team.
+type ReviewerSelector interface {
+	Select(ctx context.Context, teamName string, candidates []domain.User, count int) ([]domain.User, error)
+}
+
+type OpenAssignmentsCounter interface {
+	CountOpenAssignments(ctx context.Context, userIDs []string) (map[string]int, error)
+}
+
+// LeastLoadedSelector prefers candidates with the fewest open review assignments.
+type LeastLoadedSelector struct {
+	counter OpenAssignmentsCounter
+}
+
+func NewLeastLoadedSelector(counter OpenAssignmentsCounter) *LeastLoadedSelector {
+	return &LeastLoadedSelector{
+		counter: counter,
+	}
+}
+
+func (l *LeastLoadedSelector) Select(ctx context.Context, _ string, candidates []domain.User, count int) ([]domain.User, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		userIDs = append(userIDs, c.UserID)
+	}
+
+	counts, err := l.counter.CountOpenAssignments(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := sortedByID(candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return counts[sorted[i].UserID] < counts[sorted[j].UserID]
+	})
+	return sorted[:min(count, len(sorted))], nil
+}
+
+// RoundRobinSelector walks over the team members in a stable order, continuing
+// where the previous selection for the same team stopped. The cursor is kept in
+// memory, so every service instance has its own rotation.
+type RoundRobinSelector struct {
+	mu   sync.Mutex
+	next map[string]int
+}
+
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{
+		next: make(map[string]int),
+	}
+}
+
+func (r *RoundRobinSelector) Select(_ context.Context, teamName string, candidates []domain.User, count int) ([]domain.User, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	sorted := sortedByID(candidates)
+	count = min(count, len(sorted))
+
+	r.mu.Lock()
+	start := r.next[teamName] % len(sorted)
+	r.next[teamName] = start + count
+	r.mu.Unlock()
+
+	selected := make([]domain.User, 0, count)
+	for i := 0; i < count; i++ {
+		selected = append(selected, sorted[(start+i)%len(sorted)])
+	}
+	return selected, nil
+}
+
+// RandomSelector picks candidates uniformly at random.
+type RandomSelector struct{}
+
+func NewRandomSelector() *RandomSelector {
+	return &RandomSelector{}
+}
+
+func (RandomSelector) Select(_ context.Context, _ string, candidates []domain.User, count int) ([]domain.User, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	shuffled := make([]domain.User, len(candidates))
+	copy(shuffled, candidates)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled[:min(count, len(shuffled))], nil
+}
+
+func sortedByID(users []domain.User) []domain.User {
+	sorted := make([]domain.User, len(users))
+	copy(sorted, users)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].UserID < sorted[j].UserID
+	})
+	return sorted
+}
+
+func (s *Service) selectorFor(strategy domain.ReviewerStrategy) ReviewerSelector {
+	if selector, ok := s.selectors[strategy]; ok {
+		return selector
+	}
+	return s.selectors[domain.DefaultReviewerStrategy]
+}
+
+// reviewerSource supplies candidate pools and the selector used by pickReviewers.
+type reviewerSource struct {
+	members  func(ctx context.Context, teamName string) ([]domain.User, error)
+	selector ReviewerSelector
+}
+
+func (s *Service) reviewerSource(strategy domain.ReviewerStrategy) *reviewerSource {
+	return &reviewerSource{
+		members: func(ctx context.Context, teamName string) ([]domain.User, error) {
+			return s.prs.GetActiveTeamMembers(ctx, teamName, "")
+		},
+		selector: s.selectorFor(strategy),
+	}
+}
+
+// pickReviewers selects up to count reviewers for a PR of the team in settings.
+// When the team itself can't fill them, active members of its fallback teams
+// are considered in priority order. The author and excluded users are skipped.
+func (s *Service) pickReviewers(
+	ctx context.Context,
+	source *reviewerSource,
+	settings *domain.TeamSettings,
+	authorID string,
+	exclude []string,
+	count int,
+) ([]domain.Reviewer, error) {
+	skip := make(map[string]bool, len(exclude)+1)
+	skip[authorID] = true
+	for _, userID := range exclude {
+		skip[userID] = true
+	}
+
+	teams := append([]string{settings.TeamName}, settings.FallbackTeams...)
+
+	reviewers := make([]domain.Reviewer, 0, count)
+	for _, teamName := range teams {
+		if len(reviewers) >= count {
+			break
+		}
+
+		members, err := source.members(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]domain.User, 0, len(members))
+		for _, m := range members {
+			if !skip[m.UserID] {
+				candidates = append(candidates, m)
+			}
+		}
+
+		selected, err := source.selector.Select(ctx, teamName, candidates, count-len(reviewers))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range selected {
+			skip[u.UserID] = true
+			reviewers = append(reviewers, domain.Reviewer{UserID: u.UserID, TeamName: teamName})
+		}
+	}
+	return reviewers, nil
+}
+
+// selectInitialReviewers picks the reviewers a PR of teamName gets when it
+// becomes open for review, according to the team's settings.
+func (s *Service) selectInitialReviewers(ctx context.Context, teamName, authorID string) ([]domain.Reviewer, error) {
+	settings, err := s.teams.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	source := s.reviewerSource(settings.ReviewerStrategy)
+	return s.pickReviewers(ctx, source, settings, authorID, nil, settings.RequiredReviewers)
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticCounter map[string]int
+
+func (c staticCounter) CountOpenAssignments(_ context.Context, _ []string) (map[string]int, error) {
+	return c, nil
+}
+
+func usersFromIDs(ids ...string) []domain.User {
+	users := make([]domain.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, domain.User{UserID: id, IsActive: true})
+	}
+	return users
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+func TestLeastLoadedSelector(t *testing.T) {
+	ctx := context.Background()
+	selector := service.NewLeastLoadedSelector(staticCounter{"u1": 3, "u2": 0, "u3": 1})
+
+	t.Run("pick candidates with fewest open assignments", func(t *testing.T) {
+		selected, err := selector.Select(ctx, "backend", usersFromIDs("u1", "u2", "u3"), 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u2", "u3"}, userIDs(selected))
+	})
+
+	t.Run("return all candidates when there are not enough", func(t *testing.T) {
+		selected, err := selector.Select(ctx, "backend", usersFromIDs("u1"), 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1"}, userIDs(selected))
+	})
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	ctx := context.Background()
+	selector := service.NewRoundRobinSelector()
+	candidates := usersFromIDs("u3", "u1", "u2")
+
+	first, err := selector.Select(ctx, "backend", candidates, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, userIDs(first))
+
+	second, err := selector.Select(ctx, "backend", candidates, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3", "u1"}, userIDs(second))
+
+	t.Run("keep a separate rotation per team", func(t *testing.T) {
+		selected, err := selector.Select(ctx, "frontend", candidates, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u1"}, userIDs(selected))
+	})
+}
+
+func TestRandomSelector(t *testing.T) {
+	ctx := context.Background()
+	selector := service.NewRandomSelector()
+	candidates := usersFromIDs("u1", "u2", "u3")
+
+	selected, err := selector.Select(ctx, "backend", candidates, 2)
+	require.NoError(t, err)
+	assert.Len(t, selected, 2)
+	assert.NotEqual(t, selected[0].UserID, selected[1].UserID)
+	assert.Subset(t, []string{"u1", "u2", "u3"}, userIDs(selected))
+
+	selected, err = selector.Select(ctx, "backend", nil, 2)
+	require.NoError(t, err)
+	assert.Empty(t, selected)
+}
@@ -1,22 +1,84 @@
 package service
 
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"time"
+
+	"github.com/theartofdevel/logging"
+)
+
 type Service struct {
-	users  UserRepositoryInterface
-	teams  TeamRepositoryInterface
-	prs    PullRequestRepositoryInterface
-	logger LoggerInterfaces
+	users     UserRepositoryInterface
+	teams     TeamRepositoryInterface
+	prs       PullRequestRepositoryInterface
+	webhooks  WebhookRepositoryInterface
+	logger    LoggerInterfaces
+	metrics   MetricsRecorder
+	selectors map[domain.ReviewerStrategy]ReviewerSelector
+
+	idempotencyKeyTTL time.Duration
 }
 
 func NewService(
 	users UserRepositoryInterface,
 	teams TeamRepositoryInterface,
 	prs PullRequestRepositoryInterface,
+	webhooks WebhookRepositoryInterface,
 	logger LoggerInterfaces,
+	opts ...Option,
 ) *Service {
-	return &Service{
-		users:  users,
-		teams:  teams,
-		prs:    prs,
-		logger: logger,
+	s := &Service{
+		users:    users,
+		teams:    teams,
+		prs:      prs,
+		webhooks: webhooks,
+		logger:   logger,
+		metrics:  noopMetrics{},
+		selectors: map[domain.ReviewerStrategy]ReviewerSelector{
+			domain.ReviewerStrategyLeastLoaded: NewLeastLoadedSelector(prs),
+			domain.ReviewerStrategyRoundRobin:  NewRoundRobinSelector(),
+			domain.ReviewerStrategyRandom:      NewRandomSelector(),
+		},
+		idempotencyKeyTTL: DefaultIdempotencyKeyTTL,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type Option func(*Service)
+
+// WithMetrics makes the service report business events to metrics.
+func WithMetrics(metrics MetricsRecorder) Option {
+	return func(s *Service) {
+		s.metrics = metrics
+	}
+}
+
+// WithIdempotencyKeyTTL sets how long idempotency keys are kept before the
+// cleanup removes them.
+func WithIdempotencyKeyTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		s.idempotencyKeyTTL = ttl
+	}
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) PullRequestCreated()     {}
+func (noopMetrics) PullRequestMerged()      {}
+func (noopMetrics) ReviewersReassigned(int) {}
+func (noopMetrics) NoCandidate(int)         {}
+
+// log returns the request-scoped logger that the HTTP middleware put into ctx,
+// so that log lines carry the request id. Outside of a request it falls back
+// to the logger the service was created with.
+func (s *Service) log(ctx context.Context) LoggerInterfaces {
+	if domain.RequestIDFromContext(ctx) == "" {
+		return s.logger
 	}
+	return logging.L(ctx)
 }
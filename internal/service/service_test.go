@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/theartofdevel/logging"
+)
+
+type countingLogger struct {
+	mockLogger
+	calls int
+}
+
+func (c *countingLogger) Info(string, ...any)  { c.calls++ }
+func (c *countingLogger) Error(string, ...any) { c.calls++ }
+
+func TestService_Log(t *testing.T) {
+	t.Run("use the service logger outside of a request", func(t *testing.T) {
+		logger := &countingLogger{}
+		svc := service.NewService(nil, nil, nil, nil, logger)
+
+		_, err := svc.GetPullRequest(context.Background(), "")
+		assertAppError(t, err, domain.CodeInvalidRequest)
+		assert.NotZero(t, logger.calls)
+	})
+
+	t.Run("use the request logger inside of a request", func(t *testing.T) {
+		logger := &countingLogger{}
+		svc := service.NewService(nil, nil, nil, nil, logger)
+
+		var buf bytes.Buffer
+		requestLogger := slog.New(slog.NewJSONHandler(&buf, nil)).With(logging.StringAttr("request_id", "req-42"))
+		ctx := domain.ContextWithRequestID(context.Background(), "req-42")
+		ctx = logging.ContextWithLogger(ctx, requestLogger)
+
+		_, err := svc.GetPullRequest(ctx, "")
+		assertAppError(t, err, domain.CodeInvalidRequest)
+		assert.Zero(t, logger.calls)
+		assert.Contains(t, buf.String(), `"request_id":"req-42"`)
+		assert.Contains(t, buf.String(), "failed to get pull request")
+	})
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+
+	"github.com/theartofdevel/logging"
+)
+
+func (s *Service) GetStats(ctx context.Context) (*domain.Stats, error) {
+	s.log(ctx).Info("attempt to get stats")
+
+	reviewers, err := s.prs.GetReviewerStats(ctx)
+	if err != nil {
+		s.log(ctx).Error("failed to get reviewer stats", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	authors, err := s.prs.GetAuthorStats(ctx)
+	if err != nil {
+		s.log(ctx).Error("failed to get author stats", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	teams, err := s.prs.GetTeamStats(ctx)
+	if err != nil {
+		s.log(ctx).Error("failed to get team stats", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	avgMergeTime, err := s.prs.GetAverageMergeTime(ctx)
+	if err != nil {
+		s.log(ctx).Error("failed to get average merge time", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	s.log(ctx).Info("stats was successfully received",
+		logging.IntAttr("count reviewers", len(reviewers)),
+		logging.IntAttr("count authors", len(authors)),
+		logging.IntAttr("count teams", len(teams)),
+	)
+
+	return &domain.Stats{
+		Reviewers:           reviewers,
+		Authors:             authors,
+		Teams:               teams,
+		AvgMergeTimeSeconds: avgMergeTime,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"ReilBleem13/pull_requests_service/internal/repository"
+	"ReilBleem13/pull_requests_service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GetStats_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	t.Run("return empty stats when there are no pull requests", func(t *testing.T) {
+		stats, err := svc.GetStats(ctx)
+
+		assert.NoError(t, err)
+		assert.Empty(t, stats.Reviewers)
+		assert.Empty(t, stats.Authors)
+		assert.Empty(t, stats.Teams)
+		assert.Zero(t, stats.AvgMergeTimeSeconds)
+	})
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend'), ('frontend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author-1', 'alice', true),
+		('rev-1', 'bob', true),
+		('rev-2', 'charlie', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author-1'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status, created_at, merged_at) VALUES
+		('pr-1', 'Open PR', 'author-1', 'backend', 'OPEN', NOW(), NULL),
+		('pr-2', 'Merged PR', 'author-1', 'backend', 'MERGED', NOW() - INTERVAL '2 hours', NOW()),
+		('pr-3', 'Merged PR', 'rev-1', 'backend', 'MERGED', NOW() - INTERVAL '4 hours', NOW());
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES
+		('pr-1', 'rev-1'),
+		('pr-1', 'rev-2'),
+		('pr-2', 'rev-1'),
+		('pr-3', 'rev-2');
+	`)
+	require.NoError(t, err)
+
+	t.Run("aggregate assignments, authors and teams", func(t *testing.T) {
+		stats, err := svc.GetStats(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, stats.Reviewers, 2)
+		for _, r := range stats.Reviewers {
+			assert.Equal(t, 1, r.OpenAssignments, r.UserID)
+			assert.Equal(t, 1, r.MergedAssignments, r.UserID)
+		}
+
+		require.Len(t, stats.Authors, 2)
+		assert.Equal(t, "author-1", stats.Authors[0].AuthorID)
+		assert.Equal(t, 2, stats.Authors[0].PullRequests)
+		assert.Equal(t, 1, stats.Authors[0].OpenPullRequests)
+		assert.Equal(t, 1, stats.Authors[0].MergedPullRequests)
+
+		require.Len(t, stats.Teams, 2)
+		assert.Equal(t, "backend", stats.Teams[0].TeamName)
+		assert.Equal(t, 3, stats.Teams[0].PullRequests)
+		assert.Equal(t, 4, stats.Teams[0].Assignments)
+		assert.Equal(t, "frontend", stats.Teams[1].TeamName)
+		assert.Zero(t, stats.Teams[1].PullRequests)
+
+		assert.InDelta(t, 3*60*60, stats.AvgMergeTimeSeconds, 60)
+	})
+
+	t.Run("keep team assignments when reviewer changes team", func(t *testing.T) {
+		_, err := db.Exec(`UPDATE team_members SET team_name = 'frontend' WHERE user_id = 'rev-2'`)
+		require.NoError(t, err)
+
+		stats, err := svc.GetStats(ctx)
+		require.NoError(t, err)
+
+		require.Len(t, stats.Teams, 2)
+		assert.Equal(t, 4, stats.Teams[0].Assignments)
+		assert.Zero(t, stats.Teams[1].Assignments)
+	})
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"slices"
+
+	"github.com/theartofdevel/logging"
+)
+
+// AddTeamMembers adds users to the team and returns its members afterwards.
+func (s *Service) AddTeamMembers(ctx context.Context, teamName string, users []domain.User) ([]domain.User, error) {
+	s.log(ctx).Info("attempt to add team members",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(users)),
+	)
+
+	var v domain.Validation
+	v.Required("team_name", teamName)
+	validateMembers(&v, users)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to add team members",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	if err := s.teams.AddMembers(ctx, teamName, users); err != nil {
+		s.log(ctx).Error("failed to add team members",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	members, err := s.teams.Get(ctx, teamName)
+	if err != nil {
+		s.log(ctx).Error("failed to get team after adding members",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.log(ctx).Info("team members were successfully added",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(users)),
+	)
+	return members, nil
+}
+
+// RemoveTeamMembers removes users from the team. With reassignReviews their
+// open reviews taken for this team are handed to other candidates, the same
+// way DeactivateTeamUsers does it; otherwise the reviews are left as they are.
+func (s *Service) RemoveTeamMembers(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	reassignReviews bool,
+) (*domain.RemovalReport, error) {
+	s.log(ctx).Info("attempt to remove team members",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(userIDs)),
+		logging.BoolAttr("reassign_reviews", reassignReviews),
+	)
+
+	var v domain.Validation
+	v.Required("team_name", teamName)
+	validateUserIDs(&v, userIDs)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to remove team members",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+	userIDs = slices.Compact(slices.Sorted(slices.Values(userIDs)))
+
+	reassignments := make([]domain.Reassignment, 0)
+	unassigned := make([]domain.UnassignedReview, 0)
+
+	if reassignReviews {
+		pullRequests, err := s.prs.GetOpenPullRequestsByReviewers(ctx, userIDs)
+		if err != nil {
+			s.log(ctx).Error("failed to remove team members, failed to get open reviews",
+				logging.StringAttr("team_name", teamName),
+				logging.ErrAttr(err),
+			)
+			return nil, err
+		}
+
+		reassignments, unassigned, err = s.planReassignments(ctx, pullRequests, userIDs, teamName)
+		if err != nil {
+			s.log(ctx).Error("failed to remove team members, failed to plan reassignments",
+				logging.StringAttr("team_name", teamName),
+				logging.ErrAttr(err),
+			)
+			return nil, err
+		}
+	}
+
+	// reviews of PRs merged or closed since planning are left as they are
+	reassignments, err := s.teams.RemoveMembers(ctx, teamName, userIDs, reassignments)
+	if err != nil {
+		s.log(ctx).Error("failed to remove team members",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.metrics.ReviewersReassigned(len(reassignments))
+	s.metrics.NoCandidate(len(unassigned))
+	s.log(ctx).Info("team members were successfully removed",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(userIDs)),
+		logging.IntAttr("count reassigned", len(reassignments)),
+		logging.IntAttr("count without candidate", len(unassigned)),
+	)
+
+	return &domain.RemovalReport{
+		TeamName:         teamName,
+		RemovedUserIDs:   userIDs,
+		Reassigned:       reassignments,
+		WithoutCandidate: unassigned,
+	}, nil
+}
+
+func (s *Service) MoveTeamMember(ctx context.Context, userID, fromTeam, toTeam string) error {
+	s.log(ctx).Info("attempt to move team member",
+		logging.StringAttr("user_id", userID),
+		logging.StringAttr("from_team", fromTeam),
+		logging.StringAttr("to_team", toTeam),
+	)
+
+	var v domain.Validation
+	v.Required("user_id", userID)
+	v.Required("from_team", fromTeam)
+	v.Required("to_team", toTeam)
+	v.Check(fromTeam == "" || fromTeam != toTeam, "to_team", domain.RuleInvalid, "from_team and to_team must differ")
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to move team member",
+			logging.StringAttr("user_id", userID),
+			logging.ErrAttr(err),
+		)
+		return err
+	}
+
+	if err := s.teams.MoveMember(ctx, userID, fromTeam, toTeam); err != nil {
+		s.log(ctx).Error("failed to move team member",
+			logging.StringAttr("user_id", userID),
+			logging.ErrAttr(err),
+		)
+		return err
+	}
+
+	s.log(ctx).Info("team member was successfully moved",
+		logging.StringAttr("user_id", userID),
+		logging.StringAttr("from_team", fromTeam),
+		logging.StringAttr("to_team", toTeam),
+	)
+	return nil
+}
+
+// SyncTeam makes the team consist of exactly the given members, creating the
+// team if needed. Open reviews of removed members are left as they are. With
+// dryRun only the diff is computed.
+func (s *Service) SyncTeam(ctx context.Context, teamName string, members []domain.User, dryRun bool) (*domain.TeamSyncDiff, error) {
+	s.log(ctx).Info("attempt to sync team",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(members)),
+		logging.BoolAttr("dry_run", dryRun),
+	)
+
+	var v domain.Validation
+	if v.Required("team_name", teamName) && !domain.IsValidID(teamName) {
+		// only a team the sync creates has to follow the rules of new ids
+		if _, err := s.teams.GetSettings(ctx, teamName); domain.HasCode(err, domain.CodeNotFound) {
+			v.ID("team_name", teamName)
+		}
+	}
+	validateMembers(&v, members)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to sync team",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	diff, err := s.teams.SyncTeam(ctx, teamName, members, dryRun)
+	if err != nil {
+		s.log(ctx).Error("failed to sync team",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.log(ctx).Info("team was successfully synced",
+		logging.StringAttr("team_name", teamName),
+		logging.BoolAttr("dry_run", dryRun),
+		logging.BoolAttr("team_created", diff.TeamCreated),
+		logging.IntAttr("added", len(diff.Added)),
+		logging.IntAttr("updated", len(diff.Updated)),
+		logging.IntAttr("removed", len(diff.Removed)),
+	)
+	return diff, nil
+}
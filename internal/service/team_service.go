@@ -5,40 +5,48 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/theartofdevel/logging"
 )
 
-func (s *Service) CreateTeam(ctx context.Context, teamName string, users []domain.User) error {
-	s.logger.Info("attempt to create team",
+func (s *Service) CreateTeam(ctx context.Context, teamName string, users []domain.User, settings domain.TeamSettings) error {
+	s.log(ctx).Info("attempt to create team",
 		logging.StringAttr("team_name", teamName),
 		logging.IntAttr("quantity of users", len(users)),
 	)
 
-	if teamName == "" {
-		s.logger.Error("failed to create team",
-			logging.StringAttr("error", "team_name is empty"),
-		)
-		return domain.ErrInvalidRequest("team_name is empty")
+	if settings.ReviewerStrategy == "" {
+		settings.ReviewerStrategy = domain.DefaultReviewerStrategy
+	}
+
+	if settings.RequiredReviewers == 0 {
+		settings.RequiredReviewers = domain.DefaultRequiredReviewers
 	}
 
-	if len(users) == 0 {
-		s.logger.Error("failed to create team",
+	var v domain.Validation
+	v.ID("team_name", teamName)
+	validateMembers(&v, users)
+	validateTeamSettings(&v, teamName, settings.ReviewerStrategy, settings.RequiredReviewers, settings.RequiredApprovals, settings.FallbackTeams)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to create team",
 			logging.StringAttr("team_name", teamName),
-			logging.StringAttr("error", "count of users is 0"),
+			logging.ErrAttr(err),
 		)
-		return domain.ErrInvalidRequest("team_users is empty")
+		return err
 	}
+	settings.TeamName = teamName
 
-	if err := s.teams.Create(ctx, teamName, users); err != nil {
-		s.logger.Error("failed to create team",
+	if err := s.teams.Create(ctx, teamName, users, settings); err != nil {
+		s.log(ctx).Error("failed to create team",
 			logging.StringAttr("team_name", teamName),
 			logging.ErrAttr(err),
 		)
 		return err
 	}
 
-	s.logger.Info("team was succeccfully created",
+	s.log(ctx).Info("team was succeccfully created",
 		logging.StringAttr("team_name", teamName),
 		logging.IntAttr("quantity of users", len(users)),
 	)
@@ -46,13 +54,13 @@ func (s *Service) CreateTeam(ctx context.Context, teamName string, users []domai
 }
 
 func (s *Service) GetTeam(ctx context.Context, teamName string) ([]domain.User, error) {
-	s.logger.Info("attempt to get team members",
+	s.log(ctx).Info("attempt to get team members",
 		logging.StringAttr("team_name", teamName),
 	)
 
 	teamMembers, err := s.teams.Get(ctx, teamName)
 	if err != nil {
-		s.logger.Error("failed to get team members",
+		s.log(ctx).Error("failed to get team members",
 			logging.StringAttr("team_name", teamName),
 			logging.ErrAttr(err),
 		)
@@ -64,14 +72,185 @@ func (s *Service) GetTeam(ctx context.Context, teamName string) ([]domain.User,
 	}
 
 	if len(teamMembers) == 0 {
-		s.logger.Info("team exists but has no members",
+		s.log(ctx).Info("team exists but has no members",
 			logging.StringAttr("team_name", teamName))
 		return nil, domain.ErrNotFound()
 	}
 
-	s.logger.Info("team members was successfully received",
+	s.log(ctx).Info("team members was successfully received",
 		logging.StringAttr("team_name", teamName),
 		logging.IntAttr("quantity of team members", len(teamMembers)),
 	)
 	return teamMembers, nil
 }
+
+func (s *Service) UpdateTeamSettings(ctx context.Context, teamName string, update domain.TeamSettingsUpdate) (*domain.TeamSettings, error) {
+	s.log(ctx).Info("attempt to update team settings",
+		logging.StringAttr("team_name", teamName),
+	)
+
+	if update.ReviewerStrategy == nil && update.RequiredReviewers == nil &&
+		update.RequiredApprovals == nil && update.FallbackTeams == nil {
+		s.log(ctx).Error("failed to update team settings",
+			logging.StringAttr("team_name", teamName),
+			logging.StringAttr("error", "nothing to update"),
+		)
+		return nil, domain.ErrInvalidRequest("nothing to update")
+	}
+
+	strategy := domain.DefaultReviewerStrategy
+	if update.ReviewerStrategy != nil {
+		strategy = *update.ReviewerStrategy
+	}
+
+	requiredReviewers := domain.DefaultRequiredReviewers
+	if update.RequiredReviewers != nil {
+		requiredReviewers = *update.RequiredReviewers
+	}
+
+	requiredApprovals := 0
+	if update.RequiredApprovals != nil {
+		requiredApprovals = *update.RequiredApprovals
+	}
+
+	var v domain.Validation
+	v.Required("team_name", teamName)
+	validateTeamSettings(&v, teamName, strategy, requiredReviewers, requiredApprovals, update.FallbackTeams)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to update team settings",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	settings, err := s.teams.UpdateSettings(ctx, teamName, update)
+	if err != nil {
+		s.log(ctx).Error("failed to update team settings",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.log(ctx).Info("team settings was successfully updated",
+		logging.StringAttr("team_name", teamName),
+		logging.StringAttr("reviewer_strategy", string(settings.ReviewerStrategy)),
+		logging.IntAttr("required_reviewers", settings.RequiredReviewers),
+		logging.IntAttr("required_approvals", settings.RequiredApprovals),
+		logging.IntAttr("count fallback teams", len(settings.FallbackTeams)),
+	)
+	return settings, nil
+}
+
+func validateTeamSettings(
+	v *domain.Validation,
+	teamName string,
+	strategy domain.ReviewerStrategy,
+	requiredReviewers, requiredApprovals int,
+	fallbackTeams []string,
+) {
+	v.Check(strategy.IsValid(), "reviewer_strategy", domain.RuleEnum, "reviewer_strategy is invalid")
+	v.Check(requiredReviewers >= 1, "required_reviewers", domain.RuleRange, "required_reviewers must be positive")
+	v.Check(requiredApprovals >= 0, "required_approvals", domain.RuleRange, "required_approvals must not be negative")
+
+	seen := make(map[string]bool, len(fallbackTeams))
+	for i, fallbackTeam := range fallbackTeams {
+		field := fmt.Sprintf("fallback_teams[%d]", i)
+		if fallbackTeam == teamName || seen[fallbackTeam] {
+			v.Add(field, domain.RuleUnique, "fallback_teams must be unique names of other teams")
+			continue
+		}
+		v.Required(field, fallbackTeam)
+		seen[fallbackTeam] = true
+	}
+}
+
+// validateMembers checks the members of a team request: at least one, each
+// with a valid and unique user_id and a username.
+func validateMembers(v *domain.Validation, members []domain.User) {
+	v.Check(len(members) > 0, "members", domain.RuleRequired, "members is empty")
+
+	seen := make(map[string]bool, len(members))
+	for i, member := range members {
+		field := fmt.Sprintf("members[%d]", i)
+		v.ID(field+".user_id", member.UserID)
+		v.Name(field+".username", member.Username)
+
+		if member.UserID != "" && seen[member.UserID] {
+			v.Add(field+".user_id", domain.RuleUnique, "members must have unique user_id")
+		}
+		seen[member.UserID] = true
+	}
+}
+
+// validateUserIDs checks a non-empty list of ids of existing users.
+func validateUserIDs(v *domain.Validation, userIDs []string) {
+	v.Check(len(userIDs) > 0, "user_ids", domain.RuleRequired, "user_ids is empty")
+	for i, userID := range userIDs {
+		v.Required(fmt.Sprintf("user_ids[%d]", i), userID)
+	}
+}
+
+func (s *Service) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (*domain.DeactivationReport, error) {
+	s.log(ctx).Info("attempt to deactivate team users",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(userIDs)),
+	)
+
+	var v domain.Validation
+	v.Required("team_name", teamName)
+	validateUserIDs(&v, userIDs)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to deactivate team users",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+	userIDs = slices.Compact(slices.Sorted(slices.Values(userIDs)))
+
+	pullRequests, err := s.prs.GetOpenPullRequestsByReviewers(ctx, userIDs)
+	if err != nil {
+		s.log(ctx).Error("failed to deactivate team users, failed to get open reviews",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	reassignments, unassigned, err := s.planReassignments(ctx, pullRequests, userIDs, "")
+	if err != nil {
+		s.log(ctx).Error("failed to deactivate team users, failed to plan reassignments",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	// reviews of PRs merged or closed since planning are left as they are
+	users, reassignments, err := s.teams.DeactivateMembers(ctx, teamName, userIDs, reassignments)
+	if err != nil {
+		s.log(ctx).Error("failed to deactivate team users",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.metrics.ReviewersReassigned(len(reassignments))
+	s.metrics.NoCandidate(len(unassigned))
+	s.log(ctx).Info("team users was successfully deactivated",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("quantity of users", len(users)),
+		logging.IntAttr("count reassigned", len(reassignments)),
+		logging.IntAttr("count without candidate", len(unassigned)),
+	)
+
+	return &domain.DeactivationReport{
+		TeamName:         teamName,
+		DeactivatedUsers: users,
+		Reassigned:       reassignments,
+		WithoutCandidate: unassigned,
+	}, nil
+}
@@ -5,6 +5,7 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"ReilBleem13/pull_requests_service/internal/domain"
 	"ReilBleem13/pull_requests_service/internal/repository"
@@ -21,7 +22,7 @@ func TestService_CreateTeam_Integration(t *testing.T) {
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
 
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	_, err := db.Exec(`
@@ -39,7 +40,7 @@ func TestService_CreateTeam_Integration(t *testing.T) {
 			{UserID: "bob", Username: "Bob", IsActive: true},
 		}
 
-		err := svc.CreateTeam(ctx, "golang-squad", users)
+		err := svc.CreateTeam(ctx, "golang-squad", users, domain.TeamSettings{})
 		assert.NoError(t, err)
 
 		var count int
@@ -53,30 +54,403 @@ func TestService_CreateTeam_Integration(t *testing.T) {
 	})
 
 	t.Run("fail when team_name is empty", func(t *testing.T) {
-		err := svc.CreateTeam(ctx, "", []domain.User{{UserID: "alice"}})
+		err := svc.CreateTeam(ctx, "", []domain.User{{UserID: "alice"}}, domain.TeamSettings{})
 		assert.Error(t, err)
 		assertAppError(t, err, domain.CodeInvalidRequest, "team_name is empty")
 	})
 
 	t.Run("fail when users slice is empty", func(t *testing.T) {
-		err := svc.CreateTeam(ctx, "empty-team", []domain.User{})
+		err := svc.CreateTeam(ctx, "empty-team", []domain.User{}, domain.TeamSettings{})
 		assert.Error(t, err)
-		assertAppError(t, err, domain.CodeInvalidRequest, "team_users is empty")
+		assertAppError(t, err, domain.CodeInvalidRequest, "members is empty")
+	})
+
+	t.Run("fail on unknown reviewer strategy", func(t *testing.T) {
+		users := []domain.User{{UserID: "alice", Username: "Alice", IsActive: true}}
+
+		err := svc.CreateTeam(ctx, "strategy-team", users, domain.TeamSettings{ReviewerStrategy: "by-seniority"})
+		assert.Error(t, err)
+		assertAppError(t, err, domain.CodeInvalidRequest, "reviewer_strategy is invalid")
+	})
+
+	t.Run("store default reviewer strategy", func(t *testing.T) {
+		users := []domain.User{{UserID: "bob", Username: "Bob", IsActive: true}}
+
+		err := svc.CreateTeam(ctx, "default-strategy-team", users, domain.TeamSettings{})
+		require.NoError(t, err)
+
+		var strategy string
+		err = db.Get(&strategy, `SELECT reviewer_strategy FROM teams WHERE team_name = 'default-strategy-team'`)
+		require.NoError(t, err)
+		assert.Equal(t, string(domain.DefaultReviewerStrategy), strategy)
 	})
 
 	t.Run("fail when team already exists", func(t *testing.T) {
 		users := []domain.User{{UserID: "charlie", Username: "Charlie"}}
 
-		err := svc.CreateTeam(ctx, "duplicate-team", users)
+		err := svc.CreateTeam(ctx, "duplicate-team", users, domain.TeamSettings{})
 		require.NoError(t, err)
 
-		err = svc.CreateTeam(ctx, "duplicate-team", users)
+		err = svc.CreateTeam(ctx, "duplicate-team", users, domain.TeamSettings{})
 
 		assert.Error(t, err)
 		assertAppError(t, err, domain.CodeTeamExists)
 	})
 }
 
+func TestService_UpdateTeamSettings_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`INSERT INTO teams (team_name) VALUES ('security')`)
+	require.NoError(t, err)
+
+	t.Run("update only provided fields", func(t *testing.T) {
+		required := 3
+		settings, err := svc.UpdateTeamSettings(ctx, "security", domain.TeamSettingsUpdate{RequiredReviewers: &required})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, settings.RequiredReviewers)
+		assert.Equal(t, domain.DefaultReviewerStrategy, settings.ReviewerStrategy)
+
+		strategy := domain.ReviewerStrategyRoundRobin
+		settings, err = svc.UpdateTeamSettings(ctx, "security", domain.TeamSettingsUpdate{ReviewerStrategy: &strategy})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, settings.RequiredReviewers)
+		assert.Equal(t, domain.ReviewerStrategyRoundRobin, settings.ReviewerStrategy)
+	})
+
+	t.Run("fail on non-positive required_reviewers", func(t *testing.T) {
+		required := 0
+		_, err := svc.UpdateTeamSettings(ctx, "security", domain.TeamSettingsUpdate{RequiredReviewers: &required})
+
+		assertAppError(t, err, domain.CodeInvalidRequest, "required_reviewers must be positive")
+	})
+
+	t.Run("fail when nothing to update", func(t *testing.T) {
+		_, err := svc.UpdateTeamSettings(ctx, "security", domain.TeamSettingsUpdate{})
+
+		assertAppError(t, err, domain.CodeInvalidRequest, "nothing to update")
+	})
+
+	t.Run("return ErrNotFound when team does not exist", func(t *testing.T) {
+		required := 1
+		_, err := svc.UpdateTeamSettings(ctx, "ghost-team", domain.TeamSettingsUpdate{RequiredReviewers: &required})
+
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+}
+
+func TestService_DeactivateTeamUsers_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend'), ('frontend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'Author', true),
+		('leaving-1', 'L1', true),
+		('leaving-2', 'L2', true),
+		('stay-1', 'S1', true),
+		('stay-2', 'S2', true),
+		('outsider', 'Outsider', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'leaving-1'),
+		('backend', 'leaving-2'),
+		('backend', 'stay-1'),
+		('backend', 'stay-2'),
+		('frontend', 'outsider');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status) VALUES
+		('pr-1', 'One', 'author', 'backend', 'OPEN'),
+		('pr-2', 'Two', 'author', 'backend', 'OPEN'),
+		('pr-3', 'Three', 'author', 'backend', 'OPEN'),
+		('pr-merged', 'Merged', 'author', 'backend', 'MERGED');
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id) VALUES
+		('pr-1', 'leaving-1'),
+		('pr-1', 'leaving-2'),
+		('pr-2', 'leaving-1'),
+		('pr-2', 'stay-1'),
+		('pr-3', 'stay-1'),
+		('pr-3', 'stay-2'),
+		('pr-merged', 'leaving-1');
+	`)
+	require.NoError(t, err)
+
+	t.Run("fail when user is not a member of the team", func(t *testing.T) {
+		_, err := svc.DeactivateTeamUsers(ctx, "backend", []string{"leaving-1", "outsider"})
+		assertAppError(t, err, domain.CodeNotFound)
+
+		var isActive bool
+		require.NoError(t, db.Get(&isActive, `SELECT is_active FROM users WHERE user_id = 'leaving-1'`))
+		assert.True(t, isActive)
+	})
+
+	t.Run("fail on empty user_ids", func(t *testing.T) {
+		_, err := svc.DeactivateTeamUsers(ctx, "backend", nil)
+		assertAppError(t, err, domain.CodeInvalidRequest, "user_ids is empty")
+	})
+
+	t.Run("deactivate users and reassign their open reviews", func(t *testing.T) {
+		report, err := svc.DeactivateTeamUsers(ctx, "backend", []string{"leaving-1", "leaving-2"})
+		require.NoError(t, err)
+
+		assert.Len(t, report.DeactivatedUsers, 2)
+		for _, u := range report.DeactivatedUsers {
+			assert.False(t, u.IsActive)
+		}
+
+		assert.Len(t, report.Reassigned, 3)
+		assert.Empty(t, report.WithoutCandidate)
+
+		var reviewers []string
+		require.NoError(t, db.Select(&reviewers,
+			`SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = 'pr-1'`))
+		assert.ElementsMatch(t, []string{"stay-1", "stay-2"}, reviewers)
+
+		require.NoError(t, db.Select(&reviewers,
+			`SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = 'pr-2'`))
+		assert.ElementsMatch(t, []string{"stay-1", "stay-2"}, reviewers)
+
+		require.NoError(t, db.Select(&reviewers,
+			`SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = 'pr-merged'`))
+		assert.Equal(t, []string{"leaving-1"}, reviewers)
+	})
+
+	t.Run("report reviews left without candidate", func(t *testing.T) {
+		report, err := svc.DeactivateTeamUsers(ctx, "backend", []string{"stay-1"})
+		require.NoError(t, err)
+
+		assert.Len(t, report.Reassigned, 0)
+		assert.ElementsMatch(t, []domain.UnassignedReview{
+			{PullRequestID: "pr-1", ReviewerID: "stay-1"},
+			{PullRequestID: "pr-2", ReviewerID: "stay-1"},
+			{PullRequestID: "pr-3", ReviewerID: "stay-1"},
+		}, report.WithoutCandidate)
+	})
+
+	t.Run("skip planned reassignments of PRs that are no longer open", func(t *testing.T) {
+		// the plan is stale: pr-merged was merged after it was made
+		plan := []domain.Reassignment{
+			{PullRequestID: "pr-merged", OldReviewerID: "leaving-1", NewReviewerID: "outsider", TeamName: "frontend"},
+			{PullRequestID: "pr-3", OldReviewerID: "stay-2", NewReviewerID: "outsider", TeamName: "frontend"},
+		}
+		_, applied, err := teamRepo.DeactivateMembers(ctx, "backend", []string{"stay-2"}, plan)
+		require.NoError(t, err)
+		assert.Equal(t, plan[1:], applied)
+
+		var reviewers []string
+		require.NoError(t, db.Select(&reviewers,
+			`SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = 'pr-merged'`))
+		assert.Equal(t, []string{"leaving-1"}, reviewers)
+
+		var events int
+		require.NoError(t, db.Get(&events, `
+			SELECT COUNT(*) FROM pull_request_events
+			WHERE pull_request_id = 'pr-merged' AND event_type = 'REASSIGNED'`))
+		assert.Zero(t, events)
+	})
+}
+
+func TestService_ReassignCrossTeamReviews_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend'), ('frontend');
+		INSERT INTO team_fallback_teams (team_name, fallback_team_name, priority) VALUES ('frontend', 'backend', 1);
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('back-author', 'BA', true),
+		('back-1', 'B1', true),
+		('shared', 'Shared', true),
+		('front-author', 'FA', true),
+		('front-1', 'F1', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'back-author'),
+		('backend', 'back-1'),
+		('backend', 'shared'),
+		('frontend', 'front-author'),
+		('frontend', 'front-1');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status) VALUES
+		('pr-front', 'Front', 'front-author', 'frontend', 'OPEN'),
+		('pr-front-2', 'Front 2', 'front-author', 'frontend', 'OPEN');
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name) VALUES
+		('pr-front', 'shared', 'backend'),
+		('pr-front-2', 'back-1', 'backend');
+	`)
+	require.NoError(t, err)
+
+	t.Run("pick replacement by the settings of the PR's team", func(t *testing.T) {
+		// shared reviews the frontend PR for the fallback team backend; the
+		// replacement comes from frontend first, as ReAssign would pick it
+		report, err := svc.RemoveTeamMembers(ctx, "backend", []string{"shared"}, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, []domain.Reassignment{
+			{PullRequestID: "pr-front", OldReviewerID: "shared", NewReviewerID: "front-1", TeamName: "frontend"},
+		}, report.Reassigned)
+	})
+
+	t.Run("deactivate users with reviews on PRs of other teams", func(t *testing.T) {
+		report, err := svc.DeactivateTeamUsers(ctx, "backend", []string{"back-1"})
+		require.NoError(t, err)
+
+		// the deactivated team's pool isn't used for the frontend PR
+		assert.Equal(t, []domain.Reassignment{
+			{PullRequestID: "pr-front-2", OldReviewerID: "back-1", NewReviewerID: "front-1", TeamName: "frontend"},
+		}, report.Reassigned)
+		assert.Empty(t, report.WithoutCandidate)
+	})
+}
+
+func TestService_TeamMembership_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend'), ('frontend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'Author', true),
+		('rev-1', 'R1', true),
+		('rev-2', 'R2', true),
+		('front-1', 'F1', true);
+
+		INSERT INTO team_members (team_name, user_id, joined_at) VALUES
+		('backend', 'author', '2025-01-01T00:00:00Z'),
+		('backend', 'rev-1', '2025-01-01T00:00:00Z'),
+		('frontend', 'front-1', '2025-01-01T00:00:00Z');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status) VALUES
+		('pr-1', 'One', 'author', 'backend', 'OPEN'),
+		('pr-front', 'Front', 'front-1', 'frontend', 'OPEN');
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name) VALUES
+		('pr-1', 'rev-1', 'backend'),
+		('pr-front', 'rev-1', 'frontend');
+	`)
+	require.NoError(t, err)
+
+	joinedAt := func(t *testing.T, teamName, userID string) time.Time {
+		var joined time.Time
+		require.NoError(t, db.Get(&joined,
+			`SELECT joined_at FROM team_members WHERE team_name = $1 AND user_id = $2`, teamName, userID))
+		return joined
+	}
+
+	t.Run("add new and existing members", func(t *testing.T) {
+		members, err := svc.AddTeamMembers(ctx, "backend", []domain.User{
+			{UserID: "rev-1", Username: "R1", IsActive: true},
+			{UserID: "rev-2", Username: "R2", IsActive: true},
+			{UserID: "new-hire", Username: "New", IsActive: true},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"author", "rev-1", "rev-2", "new-hire"}, userIDs(members))
+
+		assert.True(t, joinedAt(t, "backend", "rev-1").Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.WithinDuration(t, time.Now(), joinedAt(t, "backend", "new-hire"), time.Minute)
+	})
+
+	t.Run("fail to add members to unknown team", func(t *testing.T) {
+		_, err := svc.AddTeamMembers(ctx, "ghost", []domain.User{{UserID: "rev-2", Username: "R2"}})
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("remove member and reassign reviews of the team", func(t *testing.T) {
+		report, err := svc.RemoveTeamMembers(ctx, "backend", []string{"rev-1"}, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"rev-1"}, report.RemovedUserIDs)
+		require.Len(t, report.Reassigned, 1)
+		assert.Equal(t, "pr-1", report.Reassigned[0].PullRequestID)
+		assert.Contains(t, []string{"rev-2", "new-hire"}, report.Reassigned[0].NewReviewerID)
+
+		// ревью, взятое для другой команды, остаётся за пользователем
+		var reviewers []string
+		require.NoError(t, db.Select(&reviewers,
+			`SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = 'pr-front'`))
+		assert.Equal(t, []string{"rev-1"}, reviewers)
+	})
+
+	t.Run("remove member without reassigning", func(t *testing.T) {
+		report, err := svc.RemoveTeamMembers(ctx, "backend", []string{"new-hire"}, false)
+		require.NoError(t, err)
+		assert.Empty(t, report.Reassigned)
+
+		members, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.NotContains(t, userIDs(members), "new-hire")
+	})
+
+	t.Run("fail to remove non-member", func(t *testing.T) {
+		_, err := svc.RemoveTeamMembers(ctx, "backend", []string{"front-1"}, false)
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("move member to another team", func(t *testing.T) {
+		require.NoError(t, svc.MoveTeamMember(ctx, "rev-2", "backend", "frontend"))
+
+		backend, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.NotContains(t, userIDs(backend), "rev-2")
+
+		assert.WithinDuration(t, time.Now(), joinedAt(t, "frontend", "rev-2"), time.Minute)
+	})
+
+	t.Run("fail to move into a team the user is already in", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO team_members (team_name, user_id) VALUES ('backend', 'front-1')`)
+		require.NoError(t, err)
+
+		err = svc.MoveTeamMember(ctx, "front-1", "backend", "frontend")
+		assertAppError(t, err, domain.CodeInvalidRequest, "already a member")
+
+		// перенос откатился вместе с удалением из исходной команды
+		backend, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.Contains(t, userIDs(backend), "front-1")
+	})
+
+	t.Run("fail to move from a team the user is not in", func(t *testing.T) {
+		err := svc.MoveTeamMember(ctx, "author", "frontend", "backend")
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("fail to move into unknown team", func(t *testing.T) {
+		err := svc.MoveTeamMember(ctx, "author", "backend", "ghost")
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+}
+
 func TestService_GetTeam_Integration(t *testing.T) {
 	db := setupTestDatabase(t)
 
@@ -84,7 +458,7 @@ func TestService_GetTeam_Integration(t *testing.T) {
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
 
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	_, err := db.Exec(`
@@ -136,3 +510,102 @@ func TestService_GetTeam_Integration(t *testing.T) {
 		assertAppError(t, err, domain.CodeNotFound)
 	})
 }
+
+func TestService_SyncTeam_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'Author', true),
+		('rev-1', 'R1', true),
+		('rev-2', 'R2', true),
+		('leaver', 'Leaver', true);
+
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2'),
+		('backend', 'leaver');
+
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, team_name, status) VALUES
+		('pr-1', 'One', 'author', 'backend', 'OPEN');
+
+		INSERT INTO pull_request_reviewers (pull_request_id, user_id, team_name) VALUES
+		('pr-1', 'rev-2', 'backend');
+	`)
+	require.NoError(t, err)
+
+	payload := []domain.User{
+		{UserID: "author", Username: "Author", IsActive: true},
+		{UserID: "rev-1", Username: "Reviewer One", IsActive: true},
+		{UserID: "rev-2", Username: "R2", IsActive: false},
+		{UserID: "new-hire", Username: "New", IsActive: true},
+	}
+
+	t.Run("dry run returns diff without changes", func(t *testing.T) {
+		diff, err := svc.SyncTeam(ctx, "backend", payload, true)
+		require.NoError(t, err)
+
+		assert.True(t, diff.DryRun)
+		assert.False(t, diff.TeamCreated)
+		assert.Equal(t, []string{"new-hire"}, userIDs(diff.Added))
+		assert.Equal(t, []string{"leaver"}, userIDs(diff.Removed))
+		require.Len(t, diff.Updated, 2)
+		assert.Equal(t, "rev-1", diff.Updated[0].UserID)
+		assert.Equal(t, "R1", diff.Updated[0].PreviousUsername)
+		assert.Equal(t, "rev-2", diff.Updated[1].UserID)
+		assert.True(t, diff.Updated[1].PreviousIsActive)
+
+		members, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"author", "rev-1", "rev-2", "leaver"}, userIDs(members))
+	})
+
+	t.Run("apply diff", func(t *testing.T) {
+		diff, err := svc.SyncTeam(ctx, "backend", payload, false)
+		require.NoError(t, err)
+		assert.False(t, diff.DryRun)
+		assert.Len(t, diff.Updated, 2)
+
+		members, err := svc.GetTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, payload, members)
+
+		var events int
+		require.NoError(t, db.Get(&events, `
+			SELECT COUNT(*) FROM pull_request_events
+			WHERE event_type = 'ACTIVITY_CHANGED' AND reviewer_id = 'rev-2' AND NOT is_active`))
+		assert.Equal(t, 1, events)
+	})
+
+	t.Run("repeated sync is a no-op", func(t *testing.T) {
+		diff, err := svc.SyncTeam(ctx, "backend", payload, false)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Added)
+		assert.Empty(t, diff.Updated)
+		assert.Empty(t, diff.Removed)
+	})
+
+	t.Run("create missing team", func(t *testing.T) {
+		diff, err := svc.SyncTeam(ctx, "platform", []domain.User{{UserID: "rev-1", Username: "Reviewer One", IsActive: true}}, false)
+		require.NoError(t, err)
+		assert.True(t, diff.TeamCreated)
+
+		settings, err := teamRepo.GetSettings(ctx, "platform")
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultRequiredReviewers, settings.RequiredReviewers)
+	})
+
+	t.Run("reject duplicate members", func(t *testing.T) {
+		_, err := svc.SyncTeam(ctx, "backend", []domain.User{{UserID: "a"}, {UserID: "a"}}, false)
+		assertAppError(t, err, domain.CodeInvalidRequest, "unique user_id")
+	})
+}
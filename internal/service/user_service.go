@@ -9,58 +9,62 @@ import (
 	"github.com/theartofdevel/logging"
 )
 
-func (s *Service) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, string, error) {
-	s.logger.Info("attempt to set user status",
+func (s *Service) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, []string, error) {
+	s.log(ctx).Info("attempt to set user status",
 		logging.StringAttr("userID", userID),
 		logging.BoolAttr("status", isActive),
 	)
 
-	if userID == "" {
-		s.logger.Error("failed to set user status")
-		return nil, "", domain.ErrInvalidRequest("user_id is empty")
+	var v domain.Validation
+	v.Required("user_id", userID)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to set user status", logging.ErrAttr(err))
+		return nil, nil, err
 	}
 
-	user, teamName, err := s.users.SetIsActive(ctx, userID, isActive)
+	user, teamNames, err := s.users.SetIsActive(ctx, userID, isActive)
 	if err != nil {
-		s.logger.Error("failed to set user status",
+		s.log(ctx).Error("failed to set user status",
 			logging.StringAttr("userID", userID),
 			logging.ErrAttr(err),
 		)
 
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, "", domain.ErrNotFound()
+			return nil, nil, domain.ErrNotFound()
 		}
 
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	s.logger.Info("team was succeccfully set",
+	s.log(ctx).Info("team was succeccfully set",
 		logging.StringAttr("userID", userID),
 		logging.BoolAttr("status", isActive),
 	)
-	return user, teamName, nil
+	return user, teamNames, nil
 }
 
 func (s *Service) GetReview(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
-	s.logger.Info("attempt to get review",
+	s.log(ctx).Info("attempt to get review",
 		logging.StringAttr("userID", userID),
 	)
 
-	if userID == "" {
-		s.logger.Error("failed to set user status")
-		return nil, domain.ErrInvalidRequest("user_id is empty")
+	var v domain.Validation
+	v.Required("user_id", userID)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to get review", logging.ErrAttr(err))
+		return nil, err
 	}
 
 	pullRequests, err := s.prs.GetPullRequestByID(ctx, userID)
 	if err != nil {
-		s.logger.Error("failed to get pull requests",
+		s.log(ctx).Error("failed to get pull requests",
 			logging.StringAttr("userID", userID),
 			logging.ErrAttr(err),
 		)
 		return nil, err
 	}
 
-	s.logger.Info("pull request was successfully received",
+	s.log(ctx).Info("pull request was successfully received",
 		logging.StringAttr("userID", userID),
 		logging.IntAttr("count pr", len(pullRequests)),
 	)
@@ -38,6 +38,11 @@ func assertAppError(t *testing.T, err error, expectedCode domain.ErrorCode, mess
 
 func cleanupDatabase(db *sqlx.DB) {
 	tables := []string{
+		"idempotency_keys",
+		"webhook_deliveries",
+		"webhooks",
+		"pull_request_events",
+		"team_fallback_teams",
 		"pull_request_reviewers",
 		"pull_requests",
 		"team_members",
@@ -90,7 +95,11 @@ func setupTestDatabase(t *testing.T) *sqlx.DB {
 		CREATE TABLE teams (
 		    team_name   TEXT        PRIMARY KEY,
 		    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-		    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		    reviewer_strategy TEXT  NOT NULL DEFAULT 'least_loaded'
+		        CHECK (reviewer_strategy IN ('least_loaded', 'round_robin', 'random')),
+		    required_reviewers INTEGER NOT NULL DEFAULT 2 CHECK (required_reviewers > 0),
+		    required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0)
 		);
 
 		CREATE TABLE team_members (
@@ -104,18 +113,80 @@ func setupTestDatabase(t *testing.T) *sqlx.DB {
 		    pull_request_id     TEXT        PRIMARY KEY,
 		    pull_request_name   TEXT        NOT NULL,
 		    author_id           TEXT        NOT NULL REFERENCES users(user_id),
-		    status              TEXT        NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED')),
+		    team_name           TEXT        NOT NULL REFERENCES teams(team_name),
+		    status              TEXT        NOT NULL DEFAULT 'OPEN' CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
 		    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-		    merged_at           TIMESTAMPTZ NULL
+		    merged_at           TIMESTAMPTZ NULL,
+		    closed_at           TIMESTAMPTZ NULL
 		);
 
 		CREATE TABLE pull_request_reviewers (
 		    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
 		    user_id         TEXT NOT NULL REFERENCES users(user_id)         ON DELETE CASCADE,
 		    assigned_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		    team_name       TEXT NULL,
+		    decision        TEXT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+		    decided_at      TIMESTAMPTZ NULL,
 		    PRIMARY KEY (pull_request_id, user_id)
 		);
 
+		CREATE TABLE team_fallback_teams (
+		    team_name           TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+		    fallback_team_name  TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+		    priority            INTEGER NOT NULL,
+		    PRIMARY KEY (team_name, fallback_team_name),
+		    CHECK (team_name <> fallback_team_name)
+		);
+
+		CREATE TABLE pull_request_events (
+		    event_id        BIGSERIAL   PRIMARY KEY,
+		    pull_request_id TEXT        NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+		    event_type      TEXT        NOT NULL
+		        CHECK (event_type IN (
+		            'CREATED', 'ASSIGNED', 'REASSIGNED', 'MERGED', 'ACTIVITY_CHANGED', 'REVIEWED',
+		            'READY', 'CLOSED', 'REOPENED'
+		        )),
+		    actor_id        TEXT        NULL,
+		    reviewer_id     TEXT        NULL,
+		    old_reviewer_id TEXT        NULL,
+		    is_active       BOOLEAN     NULL,
+		    decision        TEXT        NULL,
+		    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+
+		CREATE TABLE webhooks (
+		    webhook_id  BIGSERIAL   PRIMARY KEY,
+		    team_name   TEXT        NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+		    url         TEXT        NOT NULL,
+		    secret      TEXT        NOT NULL,
+		    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		    UNIQUE (team_name, url)
+		);
+
+		CREATE TABLE webhook_deliveries (
+		    delivery_id     BIGSERIAL   PRIMARY KEY,
+		    webhook_id      BIGINT      NOT NULL REFERENCES webhooks(webhook_id) ON DELETE CASCADE,
+		    event_type      TEXT        NOT NULL,
+		    payload         JSONB       NOT NULL,
+		    status          TEXT        NOT NULL DEFAULT 'PENDING'
+		        CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+		    attempts        INTEGER     NOT NULL DEFAULT 0,
+		    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		    last_error      TEXT        NULL,
+		    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		    delivered_at    TIMESTAMPTZ NULL
+		);
+
+		CREATE TABLE idempotency_keys (
+		    actor_id        TEXT        NOT NULL,
+		    idempotency_key TEXT        NOT NULL,
+		    request_hash    TEXT        NOT NULL,
+		    pull_request_id TEXT        NOT NULL,
+		    response        JSONB       NOT NULL,
+		    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		    PRIMARY KEY (actor_id, idempotency_key)
+		);
+
 		CREATE INDEX idx_team_members_team_name ON team_members(team_name);
 		CREATE INDEX idx_team_members_user_id ON team_members(user_id);
 		CREATE INDEX idx_pr_status ON pull_requests(status);
@@ -142,7 +213,7 @@ func TestService_SetIsActive_Integration(t *testing.T) {
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
 
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	_, err := db.Exec(`
@@ -153,12 +224,12 @@ func TestService_SetIsActive_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("successfully deactivate user", func(t *testing.T) {
-		user, teamName, err := svc.SetIsActive(ctx, "user-123", false)
+		user, teamNames, err := svc.SetIsActive(ctx, "user-123", false)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
 		assert.False(t, user.IsActive)
-		assert.Equal(t, "backend", teamName)
+		assert.Equal(t, []string{"backend"}, teamNames)
 
 		var isActive bool
 		err = db.Get(&isActive, "SELECT is_active FROM users WHERE user_id = $1", "user-123")
@@ -187,7 +258,7 @@ func TestService_GetReview_Integration(t *testing.T) {
 	userRepo := repository.NewUserRepository(db)
 	teamRepo := repository.NewTeamRepository(db)
 	prRepo := repository.NewPullRequestRepository(db)
-	svc := service.NewService(userRepo, teamRepo, prRepo, &mockLogger{})
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
 	ctx := context.Background()
 
 	// Пользователь существует, но у него нет PR на ревью
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/theartofdevel/logging"
+)
+
+const webhookSecretBytes = 32
+
+// RegisterWebhook subscribes rawURL to lifecycle events of the team's pull
+// requests. When secret is empty a random one is generated; either way it is
+// returned only here and is used to sign every payload.
+func (s *Service) RegisterWebhook(ctx context.Context, teamName, rawURL, secret string) (*domain.Webhook, error) {
+	s.log(ctx).Info("attempt to register webhook",
+		logging.StringAttr("team_name", teamName),
+		logging.StringAttr("url", rawURL),
+	)
+
+	var v domain.Validation
+	v.Required("team_name", teamName)
+	u, err := url.Parse(rawURL)
+	v.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+		"url", domain.RuleFormat, "url must be an absolute http(s) url")
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to register webhook",
+			logging.StringAttr("team_name", teamName),
+			logging.StringAttr("url", rawURL),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	if secret == "" {
+		buf := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook, err := s.webhooks.Create(ctx, teamName, rawURL, secret)
+	if err != nil {
+		s.log(ctx).Error("failed to register webhook",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+
+	s.log(ctx).Info("webhook was successfully registered",
+		logging.StringAttr("team_name", teamName),
+		logging.IntAttr("webhook_id", int(webhook.WebhookID)),
+	)
+	return webhook, nil
+}
+
+func (s *Service) ListWebhooks(ctx context.Context, teamName string) ([]domain.Webhook, error) {
+	var v domain.Validation
+	v.Required("team_name", teamName)
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to list webhooks", logging.ErrAttr(err))
+		return nil, err
+	}
+
+	webhooks, err := s.webhooks.List(ctx, teamName)
+	if err != nil {
+		s.log(ctx).Error("failed to list webhooks",
+			logging.StringAttr("team_name", teamName),
+			logging.ErrAttr(err),
+		)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, webhookID int64) error {
+	s.log(ctx).Info("attempt to delete webhook",
+		logging.IntAttr("webhook_id", int(webhookID)),
+	)
+
+	var v domain.Validation
+	v.Check(webhookID > 0, "webhook_id", domain.RuleRange, "webhook_id is invalid")
+	if err := v.Err(); err != nil {
+		s.log(ctx).Error("failed to delete webhook", logging.ErrAttr(err))
+		return err
+	}
+
+	if err := s.webhooks.Delete(ctx, webhookID); err != nil {
+		s.log(ctx).Error("failed to delete webhook",
+			logging.IntAttr("webhook_id", int(webhookID)),
+			logging.ErrAttr(err),
+		)
+		return err
+	}
+
+	s.log(ctx).Info("webhook was successfully deleted",
+		logging.IntAttr("webhook_id", int(webhookID)),
+	)
+	return nil
+}
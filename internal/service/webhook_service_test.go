@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/repository"
+	"ReilBleem13/pull_requests_service/internal/service"
+	"ReilBleem13/pull_requests_service/internal/webhook"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RegisterWebhook_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, repository.NewWebhookRepository(db), &mockLogger{})
+	ctx := context.Background()
+
+	_, err := db.Exec(`INSERT INTO teams (team_name) VALUES ('backend')`)
+	require.NoError(t, err)
+
+	t.Run("register, list and delete", func(t *testing.T) {
+		created, err := svc.RegisterWebhook(ctx, "backend", "https://example.com/hook", "")
+		require.NoError(t, err)
+		assert.Equal(t, "backend", created.TeamName)
+		assert.Len(t, created.Secret, 64)
+
+		webhooks, err := svc.ListWebhooks(ctx, "backend")
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, created.WebhookID, webhooks[0].WebhookID)
+		assert.Empty(t, webhooks[0].Secret)
+
+		require.NoError(t, svc.DeleteWebhook(ctx, created.WebhookID))
+
+		err = svc.DeleteWebhook(ctx, created.WebhookID)
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("fail on duplicate url", func(t *testing.T) {
+		_, err := svc.RegisterWebhook(ctx, "backend", "https://example.com/dup", "s")
+		require.NoError(t, err)
+
+		_, err = svc.RegisterWebhook(ctx, "backend", "https://example.com/dup", "s")
+		assertAppError(t, err, domain.CodeWebhookExists)
+	})
+
+	t.Run("fail on unknown team", func(t *testing.T) {
+		_, err := svc.RegisterWebhook(ctx, "ghost", "https://example.com/hook", "s")
+		assertAppError(t, err, domain.CodeNotFound)
+	})
+
+	t.Run("fail on invalid url", func(t *testing.T) {
+		_, err := svc.RegisterWebhook(ctx, "backend", "ftp://example.com", "s")
+		assertAppError(t, err, domain.CodeInvalidRequest, "url must be an absolute http(s) url")
+	})
+}
+
+func TestService_WebhookOutbox_Integration(t *testing.T) {
+	db := setupTestDatabase(t)
+
+	userRepo := repository.NewUserRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	svc := service.NewService(userRepo, teamRepo, prRepo, webhookRepo, &mockLogger{})
+	ctx := context.Background()
+
+	type received struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	inbox := make(chan received, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		inbox <- received{
+			event:     r.Header.Get(webhook.EventHeader),
+			signature: r.Header.Get(webhook.SignatureHeader),
+			body:      body,
+		}
+	}))
+	defer receiver.Close()
+
+	_, err := db.Exec(`
+		INSERT INTO teams (team_name) VALUES ('backend'), ('frontend');
+		INSERT INTO users (user_id, username, is_active) VALUES
+		('author', 'alice', true),
+		('rev-1', 'bob', true),
+		('rev-2', 'carol', true),
+		('rev-3', 'dave', true);
+		INSERT INTO team_members (team_name, user_id) VALUES
+		('backend', 'author'),
+		('backend', 'rev-1'),
+		('backend', 'rev-2'),
+		('backend', 'rev-3');
+	`)
+	require.NoError(t, err)
+
+	hook, err := svc.RegisterWebhook(ctx, "backend", receiver.URL, "s3cret")
+	require.NoError(t, err)
+
+	// вебхук другой команды не должен получать события
+	_, err = svc.RegisterWebhook(ctx, "frontend", receiver.URL, "other")
+	require.NoError(t, err)
+
+	_, err = svc.CreatePullRequest(ctx, "pr-hook", "Hooks", "author", "")
+	require.NoError(t, err)
+
+	pr, _, err := svc.ReAssign(ctx, "pr-hook", "rev-1")
+	require.NoError(t, err)
+
+	_, err = svc.MergePullRequest(ctx, "pr-hook")
+	require.NoError(t, err)
+	_, err = svc.MergePullRequest(ctx, "pr-hook")
+	require.NoError(t, err)
+
+	t.Run("failed PR creation enqueues nothing", func(t *testing.T) {
+		_, err := svc.CreatePullRequest(ctx, "pr-hook", "Duplicate", "author", "")
+		assertAppError(t, err, domain.CodePRExists)
+	})
+
+	cfg := webhook.DefaultConfig
+	cfg.Timeout = time.Second
+	dispatcher := webhook.NewDispatcher(webhookRepo, &mockLogger{}, cfg)
+
+	n, err := dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	events := make([]string, 0, 3)
+	for range 3 {
+		msg := <-inbox
+		events = append(events, msg.event)
+		assert.Equal(t, webhook.Sign(hook.Secret, msg.body), msg.signature)
+
+		var payload struct {
+			Event         string `json:"event"`
+			OldReviewerID string `json:"old_reviewer_id"`
+			PullRequest   struct {
+				PullRequestID     string   `json:"pull_request_id"`
+				Status            string   `json:"status"`
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pull_request"`
+		}
+		require.NoError(t, json.Unmarshal(msg.body, &payload))
+		assert.Equal(t, msg.event, payload.Event)
+		assert.Equal(t, "pr-hook", payload.PullRequest.PullRequestID)
+
+		switch payload.Event {
+		case string(domain.WebhookEventPRReassigned):
+			assert.Equal(t, "rev-1", payload.OldReviewerID)
+			assert.ElementsMatch(t, pr.AssignedReviewers, payload.PullRequest.AssignedReviewers)
+		case string(domain.WebhookEventPRMerged):
+			assert.Equal(t, "MERGED", payload.PullRequest.Status)
+		}
+	}
+	assert.Equal(t, []string{"pr.created", "pr.reassigned", "pr.merged"}, events)
+
+	var pending int
+	require.NoError(t, db.Get(&pending, `SELECT COUNT(*) FROM webhook_deliveries WHERE status <> 'DELIVERED'`))
+	assert.Zero(t, pending)
+}
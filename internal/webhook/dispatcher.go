@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/theartofdevel/logging"
+)
+
+const (
+	SignatureHeader = "X-Signature-256"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Store is the outbox the dispatcher reads deliveries from.
+type Store interface {
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, deliveryID int64) error
+	MarkRetry(ctx context.Context, deliveryID int64, lastErr string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, deliveryID int64, lastErr string) error
+}
+
+type Logger interface {
+	Info(msg string, params ...any)
+	Warn(msg string, params ...any)
+	Error(msg string, params ...any)
+}
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is the number of attempts after which a delivery is moved to DEAD.
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout limits a single HTTP request.
+	Timeout time.Duration
+}
+
+// leaseMargin is added to the lease of a batch to cover the outbox updates
+// made between the requests.
+const leaseMargin = 30 * time.Second
+
+var DefaultConfig = Config{
+	PollInterval: time.Second,
+	BatchSize:    50,
+	MaxAttempts:  8,
+	BaseBackoff:  5 * time.Second,
+	MaxBackoff:   time.Hour,
+	Timeout:      10 * time.Second,
+}
+
+// Dispatcher sends pending outbox deliveries to the webhook endpoints.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	logger Logger
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewDispatcher(store Store, logger Logger, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Run polls the outbox until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("failed to dispatch webhooks", logging.ErrAttr(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries and returns how many were claimed.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.store.ClaimDueDeliveries(ctx, d.cfg.BatchSize, d.lease())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if err := d.deliver(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// lease is how long a claimed batch is hidden from other dispatchers. The
+// deliveries are sent one by one, so it covers every request of the batch
+// timing out, not just one.
+func (d *Dispatcher) lease() time.Duration {
+	return time.Duration(d.cfg.BatchSize)*d.cfg.Timeout + leaseMargin
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) error {
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		return d.store.MarkDelivered(ctx, delivery.DeliveryID)
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= d.cfg.MaxAttempts {
+		d.logger.Error("webhook delivery moved to dead letter",
+			logging.IntAttr("deliveryID", int(delivery.DeliveryID)),
+			logging.StringAttr("url", delivery.URL),
+			logging.IntAttr("attempts", attempts),
+			logging.ErrAttr(sendErr),
+		)
+		return d.store.MarkDead(ctx, delivery.DeliveryID, sendErr.Error())
+	}
+
+	d.logger.Warn("webhook delivery failed, will retry",
+		logging.IntAttr("deliveryID", int(delivery.DeliveryID)),
+		logging.StringAttr("url", delivery.URL),
+		logging.IntAttr("attempts", attempts),
+		logging.ErrAttr(sendErr),
+	)
+	return d.store.MarkRetry(ctx, delivery.DeliveryID, sendErr.Error(), d.now().Add(d.backoff(attempts)))
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery domain.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.DeliveryID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next attempt: BaseBackoff doubled after
+// every failed attempt, capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// Sign returns the value of the signature header for body: "sha256=" followed
+// by the hex encoded HMAC-SHA256 of the body with the webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
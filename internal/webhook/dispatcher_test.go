@@ -0,0 +1,220 @@
+package webhook_test
+
+import (
+	"ReilBleem13/pull_requests_service/internal/domain"
+	"ReilBleem13/pull_requests_service/internal/webhook"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	pending    []domain.WebhookDelivery
+	delivered  []int64
+	dead       map[int64]string
+	retryAt    map[int64]time.Time
+	retryCause map[int64]string
+	lease      time.Duration
+}
+
+func newFakeStore(deliveries ...domain.WebhookDelivery) *fakeStore {
+	return &fakeStore{
+		pending:    deliveries,
+		dead:       make(map[int64]string),
+		retryAt:    make(map[int64]time.Time),
+		retryCause: make(map[int64]string),
+	}
+}
+
+func (f *fakeStore) ClaimDueDeliveries(_ context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lease = lease
+	n := min(limit, len(f.pending))
+	claimed := f.pending[:n]
+	f.pending = f.pending[n:]
+	return claimed, nil
+}
+
+func (f *fakeStore) MarkDelivered(_ context.Context, deliveryID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delivered = append(f.delivered, deliveryID)
+	return nil
+}
+
+func (f *fakeStore) MarkRetry(_ context.Context, deliveryID int64, lastErr string, nextAttemptAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.retryAt[deliveryID] = nextAttemptAt
+	f.retryCause[deliveryID] = lastErr
+	return nil
+}
+
+func (f *fakeStore) MarkDead(_ context.Context, deliveryID int64, lastErr string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dead[deliveryID] = lastErr
+	return nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+var testConfig = webhook.Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    10,
+	MaxAttempts:  3,
+	BaseBackoff:  time.Second,
+	MaxBackoff:   3 * time.Second,
+	Timeout:      time.Second,
+}
+
+func TestDispatcher_DispatchOnce(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte(`{"event":"pr.created","pull_request":{"pull_request_id":"pr-1"}}`)
+
+	t.Run("deliver signed payload", func(t *testing.T) {
+		var (
+			gotBody    []byte
+			gotHeaders http.Header
+		)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotBody, _ = io.ReadAll(r.Body)
+			gotHeaders = r.Header.Clone()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		store := newFakeStore(domain.WebhookDelivery{
+			DeliveryID: 7,
+			URL:        receiver.URL,
+			Secret:     "s3cret",
+			EventType:  domain.WebhookEventPRCreated,
+			Payload:    payload,
+		})
+
+		n, err := webhook.NewDispatcher(store, nopLogger{}, testConfig).DispatchOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		assert.Equal(t, []int64{7}, store.delivered)
+		assert.Equal(t, payload, gotBody)
+		assert.Equal(t, "application/json", gotHeaders.Get("Content-Type"))
+		assert.Equal(t, "pr.created", gotHeaders.Get(webhook.EventHeader))
+		assert.Equal(t, "7", gotHeaders.Get(webhook.DeliveryHeader))
+		assert.Equal(t, webhook.Sign("s3cret", payload), gotHeaders.Get(webhook.SignatureHeader))
+	})
+
+	t.Run("retry with exponential backoff", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		store := newFakeStore(
+			domain.WebhookDelivery{DeliveryID: 1, URL: receiver.URL, Payload: payload, Attempts: 0},
+			domain.WebhookDelivery{DeliveryID: 2, URL: receiver.URL, Payload: payload, Attempts: 1},
+		)
+
+		before := time.Now()
+		_, err := webhook.NewDispatcher(store, nopLogger{}, testConfig).DispatchOnce(ctx)
+		require.NoError(t, err)
+
+		assert.Empty(t, store.delivered)
+		assert.Empty(t, store.dead)
+		assert.Contains(t, store.retryCause[1], "500")
+
+		// первая неудача: BaseBackoff, вторая: 2 * BaseBackoff
+		assert.WithinDuration(t, before.Add(time.Second), store.retryAt[1], 500*time.Millisecond)
+		assert.WithinDuration(t, before.Add(2*time.Second), store.retryAt[2], 500*time.Millisecond)
+	})
+
+	t.Run("move to dead letter after max attempts", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer receiver.Close()
+
+		store := newFakeStore(domain.WebhookDelivery{
+			DeliveryID: 3,
+			URL:        receiver.URL,
+			Payload:    payload,
+			Attempts:   testConfig.MaxAttempts - 1,
+		})
+
+		_, err := webhook.NewDispatcher(store, nopLogger{}, testConfig).DispatchOnce(ctx)
+		require.NoError(t, err)
+
+		assert.Empty(t, store.retryAt)
+		assert.Contains(t, store.dead[3], "502")
+	})
+
+	t.Run("lease the batch for every request timing out", func(t *testing.T) {
+		store := newFakeStore()
+
+		_, err := webhook.NewDispatcher(store, nopLogger{}, testConfig).DispatchOnce(ctx)
+		require.NoError(t, err)
+
+		assert.Greater(t, store.lease, time.Duration(testConfig.BatchSize)*testConfig.Timeout)
+	})
+
+	t.Run("retry when receiver is unreachable", func(t *testing.T) {
+		receiver := httptest.NewServer(http.NotFoundHandler())
+		url := receiver.URL
+		receiver.Close()
+
+		store := newFakeStore(domain.WebhookDelivery{DeliveryID: 4, URL: url, Payload: payload})
+
+		_, err := webhook.NewDispatcher(store, nopLogger{}, testConfig).DispatchOnce(ctx)
+		require.NoError(t, err)
+
+		assert.Contains(t, store.retryAt, int64(4))
+	})
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	store := newFakeStore(domain.WebhookDelivery{DeliveryID: 1, URL: receiver.URL, Payload: []byte(`{}`)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		webhook.NewDispatcher(store, nopLogger{}, testConfig).Run(ctx)
+	}()
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t,
+		"sha256=a777724d943eb48dc69bca8a4a6d57a04db3f9ec7e1de4e581e860265bdf3032",
+		webhook.Sign("key", []byte(`{}`)),
+	)
+}
@@ -2,6 +2,8 @@ import http from 'k6/http';
 import { sleep, check } from 'k6';
 
 const BASE_URL = 'http://localhost:8080';  
+// токен с ролью admin: создание команд и merge требуют её
+const AUTH = { 'Authorization': `Bearer ${__ENV.API_TOKEN}` };
 
 function randomBoolean(p = 0.9) {
   return Math.random() < p;
@@ -79,7 +81,7 @@ export function setup() {
 
         const payload = { team_name: teamName, members };
         const res = http.post(`${BASE_URL}/team/add`, JSON.stringify(payload), {
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...AUTH },
         });
 
         check(res, {
@@ -115,7 +117,7 @@ export function pr_lifecycle(data) {
         author_id: authorId,
     };
     const createRes = http.post(`${BASE_URL}/pullRequest/create`, JSON.stringify(createPayload), {
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...AUTH },
         tags: { name: 'pr/create' },
     });
 
@@ -143,7 +145,7 @@ export function pr_lifecycle(data) {
             old_user_id: oldReviewerId,
         };
         const reassignRes = http.post(`${BASE_URL}/pullRequest/reassign`, JSON.stringify(reassignPayload), {
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', ...AUTH },
             tags: { name: 'pr/reassign' },
         });
 
@@ -158,7 +160,7 @@ export function pr_lifecycle(data) {
 
     const mergePayload = { pull_request_id: prId };
     const mergeRes = http.post(`${BASE_URL}/pullRequest/merge`, JSON.stringify(mergePayload), {
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...AUTH },
         tags: { name: 'pr/merge' },
     });
 
@@ -181,6 +183,7 @@ export function reads(data){
     const teamName = data.teams[teamIndex].name;
 
     const getRes = http.get(`${BASE_URL}/team/get?team_name=${teamName}`, {
+        headers: AUTH,
         tags: { name: 'get' },
     });
 
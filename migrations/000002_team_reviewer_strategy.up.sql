@@ -0,0 +1,3 @@
+ALTER TABLE teams
+    ADD COLUMN reviewer_strategy TEXT NOT NULL DEFAULT 'least_loaded'
+        CHECK (reviewer_strategy IN ('least_loaded', 'round_robin', 'random'));
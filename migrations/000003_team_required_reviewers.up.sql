@@ -0,0 +1,3 @@
+ALTER TABLE teams
+    ADD COLUMN required_reviewers INTEGER NOT NULL DEFAULT 2
+        CHECK (required_reviewers > 0);
@@ -0,0 +1,3 @@
+ALTER TABLE pull_request_reviewers DROP COLUMN IF EXISTS team_name;
+
+DROP TABLE IF EXISTS team_fallback_teams;
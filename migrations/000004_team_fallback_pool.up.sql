@@ -0,0 +1,9 @@
+CREATE TABLE team_fallback_teams (
+    team_name           TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    fallback_team_name  TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    priority            INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team_name),
+    CHECK (team_name <> fallback_team_name)
+);
+
+ALTER TABLE pull_request_reviewers ADD COLUMN team_name TEXT NULL;
@@ -0,0 +1,2 @@
+CREATE INDEX idx_pr_created_at ON pull_requests(created_at, pull_request_id);
+CREATE INDEX idx_pr_merged_at ON pull_requests(merged_at) WHERE merged_at IS NOT NULL;
@@ -0,0 +1,13 @@
+CREATE TABLE pull_request_events (
+    event_id        BIGSERIAL   PRIMARY KEY,
+    pull_request_id TEXT        NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type      TEXT        NOT NULL
+        CHECK (event_type IN ('CREATED', 'ASSIGNED', 'REASSIGNED', 'MERGED', 'ACTIVITY_CHANGED')),
+    actor_id        TEXT        NULL,
+    reviewer_id     TEXT        NULL,
+    old_reviewer_id TEXT        NULL,
+    is_active       BOOLEAN     NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pr_events_pull_request_id ON pull_request_events(pull_request_id, event_id);
@@ -0,0 +1,25 @@
+CREATE TABLE webhooks (
+    webhook_id  BIGSERIAL   PRIMARY KEY,
+    team_name   TEXT        NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    url         TEXT        NOT NULL,
+    secret      TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (team_name, url)
+);
+
+CREATE TABLE webhook_deliveries (
+    delivery_id     BIGSERIAL   PRIMARY KEY,
+    webhook_id      BIGINT      NOT NULL REFERENCES webhooks(webhook_id) ON DELETE CASCADE,
+    event_type      TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT        NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ NULL
+);
+
+CREATE INDEX idx_webhooks_team_name ON webhooks(team_name);
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
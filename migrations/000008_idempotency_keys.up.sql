@@ -0,0 +1,7 @@
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT        PRIMARY KEY,
+    request_hash    TEXT        NOT NULL,
+    pull_request_id TEXT        NOT NULL,
+    response        JSONB       NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests
+    ADD COLUMN team_name TEXT NULL REFERENCES teams(team_name);
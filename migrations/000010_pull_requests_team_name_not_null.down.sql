@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pr_team_name;
+
+ALTER TABLE pull_requests
+    ALTER COLUMN team_name DROP NOT NULL;
@@ -0,0 +1,44 @@
+-- PR, созданные до появления колонки, относятся к команде автора; если автор
+-- уже ни в одной команде не состоит — к команде, для которой назначен первый
+-- ревьювер, а если она не записана — к текущей команде первого ревьювера
+UPDATE pull_requests pr
+SET team_name = COALESCE(
+    (
+        SELECT tm.team_name
+        FROM team_members tm
+        WHERE tm.user_id = pr.author_id
+        ORDER BY tm.team_name
+        LIMIT 1
+    ),
+    (
+        SELECT prr.team_name
+        FROM pull_request_reviewers prr
+        WHERE prr.pull_request_id = pr.pull_request_id AND prr.team_name IS NOT NULL
+        ORDER BY prr.assigned_at, prr.user_id
+        LIMIT 1
+    ),
+    (
+        SELECT tm.team_name
+        FROM pull_request_reviewers prr
+        JOIN team_members tm ON tm.user_id = prr.user_id
+        WHERE prr.pull_request_id = pr.pull_request_id
+        ORDER BY prr.assigned_at, prr.user_id, tm.team_name
+        LIMIT 1
+    )
+)
+WHERE pr.team_name IS NULL;
+
+-- Оставшиеся PR (ни автор, ни ревьюверы не состоят ни в одной команде)
+-- переносятся в служебную команду legacy-unassigned без участников, чтобы
+-- SET NOT NULL не падал на старых данных. Откат миграции её не удаляет.
+INSERT INTO teams (team_name)
+SELECT 'legacy-unassigned'
+WHERE EXISTS (SELECT 1 FROM pull_requests WHERE team_name IS NULL)
+ON CONFLICT (team_name) DO NOTHING;
+
+UPDATE pull_requests
+SET team_name = 'legacy-unassigned'
+WHERE team_name IS NULL;
+
+ALTER TABLE pull_requests
+    ALTER COLUMN team_name SET NOT NULL;
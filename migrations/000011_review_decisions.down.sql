@@ -0,0 +1,14 @@
+DELETE FROM pull_request_events WHERE event_type = 'REVIEWED';
+
+ALTER TABLE pull_request_events
+    DROP COLUMN IF EXISTS decision,
+    DROP CONSTRAINT pull_request_events_event_type_check,
+    ADD CONSTRAINT pull_request_events_event_type_check
+        CHECK (event_type IN ('CREATED', 'ASSIGNED', 'REASSIGNED', 'MERGED', 'ACTIVITY_CHANGED'));
+
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS required_approvals;
+
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS decided_at,
+    DROP COLUMN IF EXISTS decision;
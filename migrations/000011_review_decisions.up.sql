@@ -0,0 +1,15 @@
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN decision TEXT NULL
+        CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    ADD COLUMN decided_at TIMESTAMPTZ NULL;
+
+-- 0 — мерж без проверки апрувов
+ALTER TABLE teams
+    ADD COLUMN required_approvals INTEGER NOT NULL DEFAULT 0
+        CHECK (required_approvals >= 0);
+
+ALTER TABLE pull_request_events
+    DROP CONSTRAINT pull_request_events_event_type_check,
+    ADD CONSTRAINT pull_request_events_event_type_check
+        CHECK (event_type IN ('CREATED', 'ASSIGNED', 'REASSIGNED', 'MERGED', 'ACTIVITY_CHANGED', 'REVIEWED')),
+    ADD COLUMN decision TEXT NULL;
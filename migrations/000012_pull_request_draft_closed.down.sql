@@ -0,0 +1,15 @@
+DELETE FROM pull_request_events WHERE event_type IN ('READY', 'CLOSED', 'REOPENED');
+
+ALTER TABLE pull_request_events
+    DROP CONSTRAINT pull_request_events_event_type_check,
+    ADD CONSTRAINT pull_request_events_event_type_check
+        CHECK (event_type IN ('CREATED', 'ASSIGNED', 'REASSIGNED', 'MERGED', 'ACTIVITY_CHANGED', 'REVIEWED'));
+
+-- черновики и закрытые PR в старой схеме не представимы
+DELETE FROM pull_requests WHERE status IN ('DRAFT', 'CLOSED');
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS closed_at,
+    DROP CONSTRAINT pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check
+        CHECK (status IN ('OPEN', 'MERGED'));
@@ -0,0 +1,13 @@
+ALTER TABLE pull_requests
+    DROP CONSTRAINT pull_requests_status_check,
+    ADD CONSTRAINT pull_requests_status_check
+        CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
+    ADD COLUMN closed_at TIMESTAMPTZ NULL;
+
+ALTER TABLE pull_request_events
+    DROP CONSTRAINT pull_request_events_event_type_check,
+    ADD CONSTRAINT pull_request_events_event_type_check
+        CHECK (event_type IN (
+            'CREATED', 'ASSIGNED', 'REASSIGNED', 'MERGED', 'ACTIVITY_CHANGED', 'REVIEWED',
+            'READY', 'CLOSED', 'REOPENED'
+        ));
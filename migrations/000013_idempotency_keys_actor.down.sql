@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_idempotency_keys_created_at;
+
+-- из совпадающих ключей разных вызывающих остаётся самый ранний
+DELETE FROM idempotency_keys k
+USING idempotency_keys earlier
+WHERE earlier.idempotency_key = k.idempotency_key
+    AND (earlier.created_at, earlier.actor_id) < (k.created_at, k.actor_id);
+
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT idempotency_keys_pkey;
+
+ALTER TABLE idempotency_keys
+    DROP COLUMN actor_id,
+    ADD PRIMARY KEY (idempotency_key);
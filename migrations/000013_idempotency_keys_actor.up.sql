@@ -0,0 +1,11 @@
+-- ключи принадлежат вызывающему: у разных клиентов совпадающие ключи не
+-- конфликтуют; ключам, сохранённым до этого, субъект неизвестен
+ALTER TABLE idempotency_keys
+    ADD COLUMN actor_id TEXT NOT NULL DEFAULT '',
+    DROP CONSTRAINT idempotency_keys_pkey,
+    ADD PRIMARY KEY (actor_id, idempotency_key);
+
+ALTER TABLE idempotency_keys
+    ALTER COLUMN actor_id DROP DEFAULT;
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);